@@ -1,14 +1,18 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"log"
-	"strconv"
+	"net/http"
+	"os"
 	"sync"
 	"time"
 
 	"github.com/alvarorichard/Goanime/internal/api"
 	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/alvarorichard/Goanime/internal/server"
 	"github.com/alvarorichard/Goanime/internal/util"
 	"github.com/hugolgst/rich-go/client"
 )
@@ -18,16 +22,69 @@ const discordClientID = "1302721937717334128" // Your Discord Client ID
 func main() {
 	var animeMutex sync.Mutex
 
+	// goanime serve runs the JSON API instead of the interactive player
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		runServer(os.Args[2:])
+		return
+	}
+
 	// Parse flags to get the anime name
 	animeName, err := util.FlagParser()
 	if err != nil {
 		log.Fatalln(util.ErrorHandler(err))
 	}
+	api.SetHostConcurrency(util.CurrentConfig().HostConcurrency)
+
+	// Keep a copy of the log for bug reports when -log-file is set
+	if path := util.CurrentConfig().LogFile; path != "" {
+		closeLog, err := util.OpenLogFile(path)
+		if err != nil {
+			log.Fatalln(util.ErrorHandler(err))
+		}
+		defer func() {
+			if err := closeLog(); err != nil {
+				log.Println("Failed to close log file:", err)
+			}
+		}()
+	}
+
+	// Write a pprof profile of this run when -profile is set
+	if mode := util.CurrentConfig().Profile; mode != "" {
+		stopProfile, err := util.StartProfile(mode, fmt.Sprintf("goanime-%s.pprof", mode))
+		if err != nil {
+			log.Fatalln(util.ErrorHandler(err))
+		}
+		defer func() {
+			if err := stopProfile(); err != nil {
+				log.Println("Failed to write profile:", err)
+			}
+		}()
+	}
+
+	// Finish the interrupted batch download instead of searching
+	if util.CurrentConfig().ResumeBatch {
+		resumeBatch()
+		return
+	}
+
+	// Diagnose the source and print a bug report instead of playing
+	if util.CurrentConfig().ReportSource {
+		reportSource(animeName)
+		return
+	}
+
+	// List the search results instead of playing
+	if util.CurrentConfig().SearchOnly {
+		if err := api.SearchOnly(os.Stdout, animeName, util.CurrentConfig().JSON); err != nil {
+			log.Fatalln(util.ErrorHandler(err))
+		}
+		return
+	}
 
 	// Initialize Discord Rich Presence
 	discordEnabled := true
 	if err := client.Login(discordClientID); err != nil {
-		if util.IsDebug {
+		if util.CurrentConfig().Debug {
 			log.Println("Failed to initialize Discord Rich Presence:", err)
 
 		}
@@ -49,16 +106,53 @@ func main() {
 
 	// Fetch episodes for the anime
 	episodes, err := api.GetAnimeEpisodes(anime.URL)
-	if err != nil || len(episodes) == 0 {
-		log.Fatalln("The selected anime does not have episodes on the server.")
+	if errors.Is(err, api.ErrNoEpisodes) {
+		fmt.Printf("%s has no episodes on the server yet. Try another search result, such as the dubbed or subtitled version.\n", anime.Name)
+		return
 	}
-
-	// Check if the anime is a series or a movie/OVA
-	series, totalEpisodes, err := api.IsSeries(anime.URL)
 	if err != nil {
-		log.Fatalln("Error checking if the anime is a series:", util.ErrorHandler(err))
+		log.Fatalln("Failed to fetch episodes:", util.ErrorHandler(err))
+	}
+
+	// Enrich the episode list with titles from MyAnimeList
+	if util.CurrentConfig().EpisodeTitles || util.CurrentConfig().EpisodeTitle != "" || util.CurrentConfig().EpisodesInfo || !util.CurrentConfig().Since.IsZero() {
+		if titles, err := api.FetchEpisodeTitles(anime.MalID); err != nil {
+			// Without air dates -since would filter out every episode
+			if !util.CurrentConfig().Since.IsZero() {
+				log.Fatalln("Failed to fetch the episode air dates needed by -since:", util.ErrorHandler(err))
+			}
+			log.Println("Failed to fetch episode titles:", err)
+		} else {
+			api.MergeEpisodeTitles(episodes, titles)
+		}
+	}
+
+	// Keep only the episodes aired since -since
+	if since := util.CurrentConfig().Since; !since.IsZero() {
+		episodes = api.EpisodesAiredSince(episodes, since, util.CurrentConfig().KeepUndated)
+		if len(episodes) == 0 {
+			fmt.Printf("%s has no episodes aired since %s.\n", anime.Name, since.Format(time.DateOnly))
+			return
+		}
 	}
 
+	// List the episodes instead of playing
+	if util.CurrentConfig().EpisodesInfo {
+		if err := api.WriteEpisodesInfo(os.Stdout, episodes, util.CurrentConfig().JSON); err != nil {
+			log.Fatalln(util.ErrorHandler(err))
+		}
+		return
+	}
+
+	// Download only the episodes released since the last download
+	if util.CurrentConfig().OnlyNew {
+		downloadNewEpisodes(anime, episodes)
+		return
+	}
+
+	// Check if the anime is a series or a movie/OVA
+	series, totalEpisodes := api.IsSeriesFromEpisodes(anime.URL, episodes), len(episodes)
+
 	// Define a flag to track if the playback is paused
 	isPaused := false
 	socketPath := "/tmp/mpvsocket" // Adjust socket path as per your setup
@@ -68,17 +162,36 @@ func main() {
 	if series {
 		fmt.Printf("The selected anime is a series with %d episodes.\n", totalEpisodes)
 
-		for {
-			// Select an episode using fuzzy finder
-			selectedEpisodeURL, episodeNumberStr, err := player.SelectEpisodeWithFuzzyFinder(episodes)
+		// Pick the first episode by title when -episode-title is set, or the newest one for "latest"
+		titledIndex := -1
+		if title := util.CurrentConfig().EpisodeTitle; title != "" {
+			titledIndex, err = api.FindEpisodeByTitle(episodes, title)
 			if err != nil {
 				log.Fatalln(util.ErrorHandler(err))
 			}
-
-			selectedEpisodeNum, err := strconv.Atoi(player.ExtractEpisodeNumber(episodeNumberStr))
+		} else if util.CurrentConfig().Latest {
+			titledIndex, err = api.LatestEpisode(episodes)
 			if err != nil {
-				log.Fatalln("Error converting episode number:", util.ErrorHandler(err))
+				log.Fatalln(util.ErrorHandler(err))
 			}
+			fmt.Printf("Playing the latest episode, %s.\n", episodes[titledIndex].Number)
+		}
+
+		for {
+			// The index of the chosen entry, since entries such as a special can share the number of an episode
+			selectedIndex := titledIndex
+			if selectedIndex >= 0 {
+				titledIndex = -1
+			} else {
+				// Select an episode using fuzzy finder
+				selectedIndex, err = player.SelectEpisodeWithFuzzyFinder(episodes)
+				if err != nil {
+					log.Fatalln(util.ErrorHandler(err))
+				}
+			}
+			selectedEpisode := episodes[selectedIndex]
+			// The number parsed from the listing, which also covers labels without one such as "Episódio Final"
+			selectedEpisodeURL, episodeNumberStr, selectedEpisodeNum := selectedEpisode.URL, selectedEpisode.Number, selectedEpisode.Num
 
 			// Lock anime struct and update with selected episode
 			animeMutex.Lock()
@@ -99,8 +212,9 @@ func main() {
 			// Retrieve video URL for the selected episode
 			videoURL, err := player.GetVideoURLForEpisode(selectedEpisodeURL)
 			if err != nil {
-				log.Fatalln("Failed to extract video URL:", util.ErrorHandler(err))
+				log.Fatalln(streamFailure(err))
 			}
+			fmt.Println(playbackSummary(anime, selectedEpisodeNum, videoURL))
 
 			// Initialize a new RichPresenceUpdater for this episode if Discord is enabled
 			var updater *player.RichPresenceUpdater
@@ -122,7 +236,7 @@ func main() {
 			player.HandleDownloadAndPlay(
 				videoURL,
 				episodes,
-				selectedEpisodeNum,
+				selectedIndex,
 				anime.URL,
 				episodeNumberStr,
 				anime.MalID, // Pass the animeMalID here
@@ -159,8 +273,9 @@ func main() {
 		// Get the video URL for the movie/OVA
 		videoURL, err := player.GetVideoURLForEpisode(episodes[0].URL)
 		if err != nil {
-			log.Fatalln("Failed to extract video URL:", util.ErrorHandler(err))
+			log.Fatalln(streamFailure(err))
 		}
+		fmt.Println(playbackSummary(anime, 0, videoURL))
 
 		// Initialize a new RichPresenceUpdater for the movie if Discord is enabled
 		var updater *player.RichPresenceUpdater
@@ -182,7 +297,7 @@ func main() {
 		player.HandleDownloadAndPlay(
 			videoURL,
 			episodes,
-			1, // Episode number for movies/OVAs
+			0, // Movies/OVAs are the only episode listed, whatever their label or URL says
 			anime.URL,
 			episodes[0].Number,
 			anime.MalID, // Pass the animeMalID here
@@ -192,3 +307,125 @@ func main() {
 
 	// No need to call updater.Stop() here as it's deferred after each initialization
 }
+
+// resumeBatch downloads what is left of the last interrupted batch download.
+func resumeBatch() {
+	path, err := player.BatchJobPath()
+	if err != nil {
+		log.Fatalln(util.ErrorHandler(err))
+	}
+	job, err := player.LoadBatchJob(path)
+	if errors.Is(err, player.ErrNoBatchJob) {
+		fmt.Println("There is no interrupted batch download to resume.")
+		return
+	}
+	if err != nil {
+		log.Fatalln(util.ErrorHandler(err))
+	}
+
+	episodes, err := api.GetAnimeEpisodes(job.AnimeURL)
+	if err != nil {
+		log.Fatalln("Failed to fetch episodes:", util.ErrorHandler(err))
+	}
+	if err := player.ResumeBatchDownload(episodes, job); err != nil {
+		log.Fatalln("Failed to resume the batch download:", util.ErrorHandler(err))
+	}
+}
+
+// playbackSummary describes the selected anime, episode (0 for a movie) and stream in one line.
+func playbackSummary(anime *api.Anime, episodeNum int, videoURL string) string {
+	return player.PlaybackSummary(player.PlaybackInfo{
+		Anime:   anime.Name,
+		Episode: episodeNum,
+		Source:  api.SourceName,
+		Quality: player.VideoQuality(videoURL),
+		Audio:   player.AudioLabel(anime.Name),
+	})
+}
+
+// streamFailure describes why an episode's video URL could not be extracted, listing every attempt when all of them failed.
+func streamFailure(err error) string {
+	var noStream *player.NoPlayableStreamError
+	if errors.As(err, &noStream) && !util.CurrentConfig().Debug {
+		return noStream.Summary()
+	}
+	return "Failed to extract video URL: " + util.ErrorHandler(err)
+}
+
+// downloadNewEpisodes downloads the episodes numbered after the last one found in the anime's download folder.
+func downloadNewEpisodes(anime *api.Anime, episodes []api.Episode) {
+	downloadDir, err := player.AnimeDownloadDir(anime.URL)
+	if err != nil {
+		log.Fatalln("Failed to find the download folder:", util.ErrorHandler(err))
+	}
+
+	last, found := player.LastDownloadedEpisode(downloadDir)
+	if !found {
+		fmt.Println("No downloaded episodes found, downloading from the first episode.")
+	}
+
+	newEpisodes := player.EpisodesAfter(episodes, last)
+	if len(newEpisodes) == 0 {
+		fmt.Printf("No new episodes after episode %d.\n", last)
+		return
+	}
+
+	// Nobody is there to confirm a huge batch when -only-new runs from a script
+	if err := player.ConfirmBatchSize(len(newEpisodes), nil); err != nil {
+		log.Fatalln(util.ErrorHandler(err))
+	}
+
+	startNum, endNum := newEpisodes[0].Num, newEpisodes[len(newEpisodes)-1].Num
+	fmt.Printf("Downloading %d new episode(s): %d to %d.\n", len(newEpisodes), startNum, endNum)
+	if err := player.DownloadEpisodeRange(episodes, anime.URL, startNum, endNum); err != nil {
+		log.Fatalln("Failed to download episodes:", util.ErrorHandler(err))
+	}
+}
+
+// reportSource runs the scraping chain for animeName without prompting and prints a prefilled GitHub issue with the outcome.
+func reportSource(animeName string) {
+	report := util.NewIssueReport(fmt.Sprintf("goanime -report-source %s", animeName), "AnimeFire (animefire.plus)")
+	defer func() {
+		fmt.Println(report.Markdown())
+	}()
+
+	// Take the first search result instead of opening the fuzzy finder
+	defer api.SetAnimeSelector(func(animes []api.Anime) (*api.Anime, error) {
+		return &animes[0], nil
+	})()
+
+	anime, err := api.SearchAnime(animeName)
+	if !report.AddStep(fmt.Sprintf("Search for %q", animeName), err) {
+		return
+	}
+
+	episodes, err := api.GetAnimeEpisodes(anime.URL)
+	if !report.AddStep(fmt.Sprintf("List the episodes of %q", anime.Name), err) {
+		return
+	}
+
+	_, err = player.GetVideoURLForEpisode(episodes[0].URL)
+	report.AddStep(fmt.Sprintf("Resolve the video of episode %s", episodes[0].Number), err)
+}
+
+// runServer parses the serve flags and serves the JSON API until the process is stopped.
+func runServer(args []string) {
+	serveFlags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := serveFlags.String("addr", "127.0.0.1:8080", "address to listen on")
+	debug := serveFlags.Bool("debug", false, "enable debug mode")
+	if err := serveFlags.Parse(args); err != nil {
+		log.Fatalln(util.ErrorHandler(err))
+	}
+
+	config := util.DefaultConfig()
+	config.Debug = *debug
+	util.SetConfig(config)
+
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           server.New(server.NewScraperClient()),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	fmt.Printf("Serving the GoAnime API on http://%s\n", *addr)
+	log.Fatalln(httpServer.ListenAndServe())
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,6 +10,7 @@ import (
 	"net/url"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -76,56 +78,165 @@ type Title struct {
 	English string `json:"english"`
 }
 
+// ErrNoAnimeFound is returned when a search has no results, even after retrying with a simplified query.
+var ErrNoAnimeFound = errors.New("no anime found with the given name")
+
+// animeSelector picks one anime out of the search results. It is the fuzzy finder unless replaced with SetAnimeSelector.
+var animeSelector = selectAnimeWithGoFuzzyFinder
+
+// SetAnimeSelector replaces the interactive anime selection and returns a function restoring the previous one.
+func SetAnimeSelector(selector func(animes []Anime) (*Anime, error)) func() {
+	previous := animeSelector
+	animeSelector = selector
+	return func() {
+		animeSelector = previous
+	}
+}
+
 func SearchAnime(animeName string) (*Anime, error) {
-	currentPageURL := fmt.Sprintf("%s/pesquisar/%s", baseSiteURL, url.PathEscape(animeName))
+	query, animes, err := searchWithFallback(animeName)
+	if err != nil {
+		return nil, err
+	}
+	selectedAnime, err := selectAnime(query, animes)
+	if err != nil {
+		return nil, err
+	}
 
-	if util.IsDebug {
-		log.Printf("Searching for anime with URL: %s", currentPageURL)
+	// Busca de detalhes adicionais pela AniList API, incluindo a imagem de capa
+	aniListInfo, err := FetchAnimeFromAniList(selectedAnime.Name)
+	if err != nil {
+		log.Printf("Error fetching additional data from AniList: %v", err)
+	} else {
+		selectedAnime.AnilistID = aniListInfo.Data.Media.ID
+		selectedAnime.MalID = aniListInfo.Data.Media.IDMal
+		selectedAnime.Details = aniListInfo.Data.Media
+
+		// Definindo a imagem de capa do AniList
+		if aniListInfo.Data.Media.CoverImage.Large != "" {
+			selectedAnime.ImageURL = aniListInfo.Data.Media.CoverImage.Large
+			if util.CurrentConfig().Debug {
+				log.Printf("Cover image URL retrieved from AniList: %s", selectedAnime.ImageURL)
+			}
+		} else {
+			log.Printf("Cover image URL not found in AniList response for anime: %s", selectedAnime.Name)
+		}
+		if util.CurrentConfig().Debug {
+			log.Printf("AniList ID: %d, MAL ID: %d, Title: %s, Score: %d, Cover Image URL: %s",
+				aniListInfo.Data.Media.ID, aniListInfo.Data.Media.IDMal,
+				aniListInfo.Data.Media.Title.Romaji, aniListInfo.Data.Media.AverageScore,
+				selectedAnime.ImageURL)
+		}
 	}
 
+	return selectedAnime, nil
+}
+
+// searchWithFallback searches for animeName and, when nothing is found, once more with SimplifyQuery.
+// It returns the query that found the results, or ErrNoAnimeFound with suggestions for the user.
+func searchWithFallback(animeName string) (string, []Anime, error) {
+	query := animeName
+	animes, err := SearchAnimeResults(query)
+	if errors.Is(err, ErrNoAnimeFound) {
+		// Typos aside, most empty searches come from season words or punctuation the site doesn't index
+		if simplified := SimplifyQuery(animeName); simplified != "" && simplified != animeName {
+			log.Printf("No results for %q, retrying with %q", animeName, simplified)
+			query = simplified
+			animes, err = SearchAnimeResults(query)
+		}
+	}
+	if errors.Is(err, ErrNoAnimeFound) {
+		return "", nil, errors.Wrapf(ErrNoAnimeFound,
+			"nothing found for %q; check the spelling, try the original (romaji) title or a shorter name", animeName)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return query, FilterByMode(animes, util.CurrentConfig().Mode), nil
+}
+
+// SearchAnimeResults walks the search result pages for the query and returns the results of the first page that has any,
+// without asking the user to pick one. It returns ErrNoAnimeFound when no page has results.
+//
+// Parameters:
+// - query: the search slug, e.g. "one-piece".
+//
+// Returns:
+// - []Anime: the anime found.
+// - error: ErrNoAnimeFound, or an error if a request fails.
+func SearchAnimeResults(query string) ([]Anime, error) {
+	currentPageURL := fmt.Sprintf("%s/pesquisar/%s", baseSiteURL, url.PathEscape(query))
+
+	// Every result page is logged under one trace ID
+	ctx := util.WithTrace(context.Background())
+	util.Debugf(ctx, "Searching for anime with URL: %s", currentPageURL)
+
 	for {
-		selectedAnime, nextPageURL, err := searchAnimeOnPage(currentPageURL)
+		animes, nextPageURL, err := searchAnimeOnPage(ctx, currentPageURL)
 		if err != nil {
 			return nil, err
 		}
-		if selectedAnime != nil {
-			// Busca de detalhes adicionais pela AniList API, incluindo a imagem de capa
-			aniListInfo, err := FetchAnimeFromAniList(selectedAnime.Name)
-			if err != nil {
-				log.Printf("Error fetching additional data from AniList: %v", err)
-			} else {
-				selectedAnime.AnilistID = aniListInfo.Data.Media.ID
-				selectedAnime.MalID = aniListInfo.Data.Media.IDMal
-				selectedAnime.Details = aniListInfo.Data.Media
-
-				// Definindo a imagem de capa do AniList
-				if aniListInfo.Data.Media.CoverImage.Large != "" {
-					selectedAnime.ImageURL = aniListInfo.Data.Media.CoverImage.Large
-					if util.IsDebug {
-						log.Printf("Cover image URL retrieved from AniList: %s", selectedAnime.ImageURL)
-					}
-				} else {
-					log.Printf("Cover image URL not found in AniList response for anime: %s", selectedAnime.Name)
-				}
-				if util.IsDebug {
-					log.Printf("AniList ID: %d, MAL ID: %d, Title: %s, Score: %d, Cover Image URL: %s",
-						aniListInfo.Data.Media.ID, aniListInfo.Data.Media.IDMal,
-						aniListInfo.Data.Media.Title.Romaji, aniListInfo.Data.Media.AverageScore,
-						selectedAnime.ImageURL)
-				}
-
-			}
-
-			return selectedAnime, nil
+		if len(animes) > 0 {
+			return animes, nil
 		}
 
 		if nextPageURL == "" {
-			return nil, errors.New("no anime found with the given name")
+			return nil, ErrNoAnimeFound
 		}
 		currentPageURL = baseSiteURL + nextPageURL
 	}
 }
 
+// seasonWords are dropped by SimplifyQuery together with the number that follows them.
+var seasonWords = map[string]bool{
+	"season": true, "temporada": true, "part": true, "parte": true, "cour": true,
+}
+
+// ordinalRe matches ordinals such as "2nd" or "3rd" and season shorthands such as "s2".
+var ordinalRe = regexp.MustCompile(`^(\d+(st|nd|rd|th|a|º)|s\d+)$`)
+
+// SimplifyQuery strips punctuation and season markers ("season 2", "2nd", "part 1") from a search slug,
+// e.g. "attack-on-titan:-season-2" becomes "attack-on-titan".
+func SimplifyQuery(query string) string {
+	query = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`:;!?.,'"()[]`, r) {
+			return -1
+		}
+		return r
+	}, strings.ToLower(query))
+
+	words := strings.FieldsFunc(query, func(r rune) bool { return r == '-' || r == ' ' })
+
+	var kept []string
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if seasonWords[word] {
+			// Skip the season number as well
+			if i+1 < len(words) {
+				if _, err := strconv.Atoi(words[i+1]); err == nil || isRomanNumeral(words[i+1]) {
+					i++
+				}
+			}
+			continue
+		}
+		if ordinalRe.MatchString(word) {
+			continue
+		}
+		kept = append(kept, word)
+	}
+
+	return strings.Join(kept, "-")
+}
+
+// isRomanNumeral reports whether word is a small roman numeral as used in season names.
+func isRomanNumeral(word string) bool {
+	switch word {
+	case "i", "ii", "iii", "iv", "v", "vi":
+		return true
+	}
+	return false
+}
+
 // GetEpisodeData fetches episode data for a given anime ID and episode number from Jikan API
 func GetEpisodeData(animeID int, episodeNo int, anime *Anime) error {
 
@@ -233,9 +344,9 @@ func GetMovieData(animeID int, anime *Anime) error {
 }
 
 
-// searchAnimeOnPage searches for anime on a given page and returns the selected anime
-func searchAnimeOnPage(pageURL string) (*Anime, string, error) {
-	response, err := getHTTPResponse(pageURL)
+// searchAnimeOnPage searches for anime on a given page and returns the results, or the next page URL when it has none
+func searchAnimeOnPage(ctx context.Context, pageURL string) ([]Anime, string, error) {
+	response, err := getHTTPResponse(ctx, pageURL)
 	if err != nil {
 		return nil, "", errors.Wrap(err, "failed to perform search request")
 	}
@@ -259,16 +370,10 @@ func searchAnimeOnPage(pageURL string) (*Anime, string, error) {
 	}
 
 	animes := ParseAnimes(doc)
-	if util.IsDebug {
-		log.Printf("Number of animes found: %d", len(animes))
-	}
+	util.Debugf(ctx, "Number of animes found: %d", len(animes))
 
 	if len(animes) > 0 {
-		selectedAnime, err := selectAnimeWithGoFuzzyFinder(animes)
-		if err != nil {
-			return nil, "", err
-		}
-		return selectedAnime, "", nil
+		return animes, "", nil
 	}
 
 	nextPage, exists := doc.Find(".pagination .next a").Attr("href")
@@ -290,9 +395,9 @@ func ParseAnimes(doc *goquery.Document) []Anime {
 		}
 		url := resolveURL(baseSiteURL, urlPath)
 
-		name := strings.TrimSpace(s.Text())
+		name := NormalizeText(s.Text())
 
-		if util.IsDebug {
+		if util.CurrentConfig().Debug {
 			log.Printf("Parsed Anime - Name: %s, URL: %s", name, url)
 		}
 
@@ -307,7 +412,12 @@ func ParseAnimes(doc *goquery.Document) []Anime {
 
 // FetchAnimeDetails retrieves additional information for the selected anime
 func FetchAnimeDetails(anime *Anime) error {
-	response, err := http.Get(anime.URL)
+	req, err := http.NewRequest("GET", anime.URL, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create anime details request")
+	}
+	decorateRequest(req)
+	response, err := doerOr(&http.Client{CheckRedirect: RedirectPolicy(util.CurrentConfig().MaxRedirects)}).Do(req)
 	if err != nil {
 		return errors.Wrap(err, "failed to get anime details page")
 	}
@@ -332,13 +442,17 @@ func FetchAnimeDetails(anime *Anime) error {
 	if !exists || imageURL == "" {
 		return errors.New("cover image URL not found")
 	}
+	// Keep the AniList cover found while searching, which Discord shows in a better size
+	if anime.ImageURL == "" {
+		anime.ImageURL = resolveURL(anime.URL, imageURL)
+	}
 
 	return nil
 }
 
 func FetchAnimeFromAniList(animeName string) (*AniListResponse, error) {
 	cleanedName := CleanTitle(animeName)
-	if util.IsDebug {
+	if util.CurrentConfig().Debug {
 		log.Printf("Attempting AniList search with title: %s", cleanedName)
 
 	}
@@ -378,8 +492,7 @@ func FetchAnimeFromAniList(animeName string) (*AniListResponse, error) {
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := doerOr(&http.Client{}).Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch data from AniList API: %v", err)
 	}
@@ -410,7 +523,7 @@ func FetchAnimeFromAniList(animeName string) (*AniListResponse, error) {
 		return nil, fmt.Errorf("no results found on AniList for anime: %s", cleanedName)
 	}
 
-	if util.IsDebug {
+	if util.CurrentConfig().Debug {
 		log.Printf("AniList ID: %d, MAL ID: %d, Title: %s, Score: %d, Cover Image URL: %s",
 			result.Data.Media.ID, result.Data.Media.IDMal,
 			result.Data.Media.Title.Romaji, result.Data.Media.AverageScore,
@@ -427,12 +540,17 @@ func selectAnimeWithGoFuzzyFinder(animes []Anime) (*Anime, error) {
 	}
 
 	sortedAnimes := sortAnimes(animes)
-	idx, err := fuzzyfinder.Find(
-		sortedAnimes,
-		func(i int) string {
-			return sortedAnimes[i].Name
-		},
-	)
+	ctx, cancel := util.SelectionContext()
+	defer cancel()
+	idx, err := util.FindWithContext(ctx, func(ctx context.Context) (int, error) {
+		return fuzzyfinder.Find(
+			sortedAnimes,
+			func(i int) string {
+				return sortedAnimes[i].Name
+			},
+			fuzzyfinder.WithContext(ctx),
+		)
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to select anime with go-fuzzyfinder")
 	}
@@ -483,12 +601,12 @@ func makeGetRequest(url string, headers map[string]string) (map[string]interface
 		return nil, fmt.Errorf("failed to create GET request: %w", err)
 	}
 
+	decorateRequest(req)
 	for key, value := range headers {
 		req.Header.Set(key, value)
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := doerOr(&http.Client{}).Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send GET request: %w", err)
 	}
@@ -517,14 +635,14 @@ func makeGetRequest(url string, headers map[string]string) (map[string]interface
 	return responseData, nil
 }
 
-func getHTTPResponse(url string) (*http.Response, error) {
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", url, nil)
+func getHTTPResponse(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64)")
-	resp, err := client.Do(req)
+	decorateRequest(req)
+	resp, err := doerOr(&http.Client{CheckRedirect: RedirectPolicy(util.CurrentConfig().MaxRedirects)}).Do(req)
 	if err != nil {
 		return nil, err
 	}
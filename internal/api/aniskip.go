@@ -7,6 +7,7 @@ import (
 	"io"
 	"math"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -40,8 +41,33 @@ type skipInterval struct {
 	EndTime   float64 `json:"end_time"`
 }
 
-// GetAniSkipData fetches skip times data for a given anime ID and episode
+// aniSkipKey identifies the skip times of one episode of an anime.
+type aniSkipKey struct {
+	malID   int
+	episode int
+}
+
+var (
+	aniSkipMu    sync.Mutex
+	aniSkipCache = map[aniSkipKey]string{}
+)
+
+// GetAniSkipData fetches skip times data for a given anime ID and episode.
+// Responses are cached per anime and episode for the rest of the run, so replaying or going back to an episode,
+// streamed or downloaded, doesn't ask AniSkip again.
 func GetAniSkipData(animeMalId int, episode int) (string, error) {
+	if animeMalId == 0 {
+		return "", fmt.Errorf("anime has no MAL ID to fetch skip times with")
+	}
+
+	key := aniSkipKey{malID: animeMalId, episode: episode}
+	aniSkipMu.Lock()
+	cached, ok := aniSkipCache[key]
+	aniSkipMu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
 	baseURL := "https://api.aniskip.com/v1/skip-times"
 
 	url := fmt.Sprintf("%s/%d/%d?types=op&types=ed", baseURL, animeMalId, episode)
@@ -49,7 +75,12 @@ func GetAniSkipData(animeMalId int, episode int) (string, error) {
 		Timeout: 10 * time.Second,
 	}
 
-	resp, err := client.Get(url)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create AniSkip request: %w", err)
+	}
+
+	resp, err := doerOr(client).Do(req)
 	if err != nil {
 		return "", fmt.Errorf("error fetching data from AniSkip API: %w", err)
 	}
@@ -64,6 +95,10 @@ func GetAniSkipData(animeMalId int, episode int) (string, error) {
 		return "", fmt.Errorf("failed to read response body: %w", err)
 	}
 
+	aniSkipMu.Lock()
+	aniSkipCache[key] = string(body)
+	aniSkipMu.Unlock()
+
 	return string(body), nil
 }
 
@@ -85,7 +120,7 @@ func ParseAniSkipResponse(responseText string, episode *Episode, timePrecision i
 		return fmt.Errorf("error unmarshalling response: %w", err)
 	}
 
-	if util.IsDebug {
+	if util.CurrentConfig().Debug {
 		// Log the raw response for debugging
 		fmt.Printf("AniSkip Raw Response: %s\n", responseText)
 	}
@@ -2,16 +2,35 @@ package api
 
 import (
 	"crypto/tls"
+	"log"
 	"net"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/alvarorichard/Goanime/internal/util"
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 )
 
+// IsAllowedHost reports whether host passes the -allow-hosts allowlist, which also covers subdomains.
+// Every host is allowed when no allowlist is configured.
+func IsAllowedHost(host string) bool {
+	allowedHosts := util.CurrentConfig().AllowedHosts
+	if len(allowedHosts) == 0 {
+		return true
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, allowed := range allowedHosts {
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsDisallowedIP checks if the given IP address falls under a disallowed category.
-// It returns true if the IP address is multicast, unspecified, loopback, or private.
+// It returns true if the IP address is multicast, unspecified, loopback, link-local, or private.
 //
 // Parameters:
 // - hostIP: a string representing the IP address to check.
@@ -27,7 +46,8 @@ func IsDisallowedIP(hostIP string) bool {
 	// - IsUnspecified: returns true if the IP is unspecified (e.g., 0.0.0.0).
 	// - IsLoopback: returns true if the IP is a loopback address (e.g., 127.0.0.1).
 	// - IsPrivate: returns true if the IP is in a private range (e.g., 192.168.x.x).
-	return ip.IsMulticast() || ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate()
+	// - IsLinkLocalUnicast: returns true for link-local addresses such as the 169.254.169.254 metadata endpoint.
+	return ip.IsMulticast() || ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast()
 }
 
 // checkDisallowedIP validates the IP address of a connection to ensure it is allowed.
@@ -39,6 +59,10 @@ func IsDisallowedIP(hostIP string) bool {
 // Returns:
 // - error: an error if the IP address is disallowed or if there is an issue closing the connection.
 func checkDisallowedIP(conn net.Conn) error {
+	if util.CurrentConfig().AllowPrivateIPs {
+		return nil
+	}
+
 	// Extract the IP address from the connection's remote address.
 	ip, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
 
@@ -74,11 +98,22 @@ func checkDisallowedIP(conn net.Conn) error {
 // - net.Conn: the established network connection.
 // - error: an error if the connection fails or if the IP address is disallowed.
 func dialFunc(network, addr string, timeout time.Duration, tlsConfig *tls.Config) (net.Conn, error) {
+	// Refuse hosts outside the allowlist, and literal disallowed IPs, before opening any connection.
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if !IsAllowedHost(host) {
+		return nil, errors.Errorf("host %s is not in the allowed hosts list", host)
+	}
+	if net.ParseIP(host) != nil && !util.CurrentConfig().AllowPrivateIPs && IsDisallowedIP(host) {
+		return nil, errors.New("ip address is not allowed")
+	}
+
 	// Create a net.Dialer with the specified timeout.
 	dialer := &net.Dialer{Timeout: timeout}
 
 	var conn net.Conn
-	var err error
 
 	// If a TLS configuration is provided, use tls.DialWithDialer to establish a TLS connection.
 	// Otherwise, establish a regular network connection using dialer.Dial.
@@ -111,25 +146,108 @@ func dialFunc(network, addr string, timeout time.Duration, tlsConfig *tls.Config
 // Returns:
 // - *http.Transport: a pointer to an http.Transport configured with custom dial functions and security settings.
 func SafeTransport(timeout time.Duration) *http.Transport {
+	return SafeTransportTimeouts(timeout, 0)
+}
+
+// SafeTransportTimeouts is SafeTransport with a separate limit on how long a server may take to answer.
+// The connect timeout covers dialing and the TLS handshake, so dead hosts fail fast; the header timeout covers
+// the wait for the response headers once the request is sent. Neither limits reading the body, so a slow
+// download that keeps making progress is never cut off.
+//
+// Parameters:
+// - connectTimeout: the duration for both the connection timeout and the TLS handshake timeout.
+// - headerTimeout: how long to wait for the response headers, or 0 to wait as long as the server takes.
+//
+// Returns:
+// - *http.Transport: a pointer to an http.Transport configured with custom dial functions and security settings.
+func SafeTransportTimeouts(connectTimeout, headerTimeout time.Duration) *http.Transport {
 	// Configure TLS settings, requiring at least TLS version 1.2.
 	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
 
 	return &http.Transport{
 		// Custom dial function for regular (non-TLS) connections.
 		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
-			return dialFunc(network, addr, timeout, nil)
+			return dialFunc(network, addr, connectTimeout, nil)
 		},
 		// Custom dial function for TLS connections, using the specified TLS configuration.
 		DialTLSContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
-			return dialFunc(network, addr, timeout, tlsConfig)
+			return dialFunc(network, addr, connectTimeout, tlsConfig)
 		},
 		// Set the timeout for the TLS handshake process.
-		TLSHandshakeTimeout: timeout,
+		TLSHandshakeTimeout:   connectTimeout,
+		ResponseHeaderTimeout: headerTimeout,
+	}
+}
+
+// ConfiguredTransport returns a SafeTransportTimeouts using the -connect-timeout and -header-timeout of this run.
+func ConfiguredTransport() *http.Transport {
+	config := util.CurrentConfig()
+	return SafeTransportTimeouts(config.ConnectTimeout, config.HeaderTimeout)
+}
+
+// Doer is the part of *http.Client used by the scrapers to send requests.
+// Routing every request through a Doer lets tests replace the network with canned responses.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// httpDoer, when set, is used instead of the default clients for every outgoing request.
+var httpDoer Doer
+
+// SetHTTPDoer replaces the client used for outgoing requests and returns a function restoring the previous one.
+// Passing nil restores the default clients.
+//
+// Parameters:
+// - d: the Doer that will send every request made by the api package.
+//
+// Returns:
+// - func(): a function that restores the previously configured Doer.
+func SetHTTPDoer(d Doer) func() {
+	previous := httpDoer
+	httpDoer = d
+	return func() {
+		httpDoer = previous
+	}
+}
+
+// doerOr returns the injected Doer if one was set, otherwise the given default client.
+func doerOr(defaultClient *http.Client) Doer {
+	if httpDoer != nil {
+		return httpDoer
+	}
+	return defaultClient
+}
+
+// decorateRequest applies the per-run request settings, such as the -lang Accept-Language override, to an outgoing request.
+func decorateRequest(req *http.Request) {
+	util.Debugf(req.Context(), "%s %s", req.Method, req.URL.Host)
+	if util.CurrentConfig().AcceptLanguage != "" {
+		req.Header.Set("Accept-Language", util.CurrentConfig().AcceptLanguage)
+	}
+}
+
+// RedirectPolicy returns a CheckRedirect function that follows at most maxRedirects redirects.
+// In debug mode every hop is logged by host only, since stream URLs carry access tokens.
+//
+// Parameters:
+// - maxRedirects: the number of redirects to follow before giving up.
+//
+// Returns:
+// - func(*http.Request, []*http.Request) error: a policy suitable for http.Client.CheckRedirect.
+func RedirectPolicy(maxRedirects int) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > maxRedirects {
+			return errors.Errorf("stopped after %d redirects (last hop: %s)", maxRedirects, req.URL.Host)
+		}
+		if util.CurrentConfig().Debug {
+			log.Printf("Redirect %d/%d: %s -> %s", len(via), maxRedirects, via[len(via)-1].URL.Host, req.URL.Host)
+		}
+		return nil
 	}
 }
 
 // SafeGet performs an HTTP GET request to the specified URL using a custom HTTP client with a timeout.
-// The function returns the response or an error if the request fails.
+// The function returns the response or an error if the request fails, wrapping ErrGeoBlocked when the site refuses the user's region.
 //
 // Parameters:
 // - url: the URL to send the GET request to.
@@ -138,11 +256,60 @@ func SafeTransport(timeout time.Duration) *http.Transport {
 // - *http.Response: a pointer to the HTTP response object containing the server's response.
 // - error: an error if the request fails or if there is a problem during the request.
 func SafeGet(url string) (*http.Response, error) {
-	// Create an HTTP client with a custom transport that includes a 10-second timeout.
+	return SafeGetContext(context.Background(), url)
+}
+
+// SafeGetContext is SafeGet with a context, used to cancel the request or to log it under the trace ID of an operation.
+func SafeGetContext(ctx context.Context, url string) (*http.Response, error) {
+	// Create an HTTP client with a custom transport using the configured timeouts.
+	httpClient := &http.Client{
+		Transport:     ConfiguredTransport(),
+		CheckRedirect: RedirectPolicy(util.CurrentConfig().MaxRedirects),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	decorateRequest(req)
+
+	// Perform the GET request using the custom HTTP client (or the injected Doer).
+	resp, err := doerOr(httpClient).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	util.Debugf(ctx, "%s %s: %s", req.Method, req.URL.Host, resp.Status)
+
+	// Report region blocks as ErrGeoBlocked instead of handing the block page to the parsers.
+	return checkGeoBlock(req, resp)
+}
+
+// SafeHead performs an HTTP HEAD request to the specified URL with the same client settings as SafeGet.
+// It is used to check that a URL still answers without downloading it.
+//
+// Parameters:
+// - url: the URL to send the HEAD request to.
+//
+// Returns:
+// - *http.Response: the server's response, with an empty body.
+// - error: an error if the request fails.
+func SafeHead(url string) (*http.Response, error) {
+	return SafeHeadContext(context.Background(), url)
+}
+
+// SafeHeadContext is SafeHead with a context, like SafeGetContext.
+func SafeHeadContext(ctx context.Context, url string) (*http.Response, error) {
+	// A HEAD answer has no body, so -connect-timeout and -header-timeout bound the whole request
 	httpClient := &http.Client{
-		Transport: SafeTransport(10 * time.Second),
+		Transport:     ConfiguredTransport(),
+		CheckRedirect: RedirectPolicy(util.CurrentConfig().MaxRedirects),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "HEAD", url, nil)
+	if err != nil {
+		return nil, err
 	}
+	decorateRequest(req)
 
-	// Perform the GET request using the custom HTTP client and return the response.
-	return httpClient.Get(url)
+	return doerOr(httpClient).Do(req)
 }
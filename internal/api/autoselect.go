@@ -0,0 +1,91 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/alvarorichard/Goanime/internal/util"
+)
+
+// normalizeTitle lowercases a title or search slug and reduces it to its words, so "one-piece" and "One Piece!" compare equal.
+func normalizeTitle(title string) []string {
+	return strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// MatchConfidence scores how well an anime name matches a search query, from 0 to 1.
+// It is 1 only when both have the same words in the same order, ignoring case and punctuation; otherwise it is
+// the share of words they have in common, so "One Piece" scores higher than "One Piece Film: Red" for "one-piece".
+func MatchConfidence(query, name string) float64 {
+	queryWords, nameWords := normalizeTitle(query), normalizeTitle(name)
+	if len(queryWords) == 0 || len(nameWords) == 0 {
+		return 0
+	}
+	if strings.Join(queryWords, " ") == strings.Join(nameWords, " ") {
+		return 1
+	}
+
+	inName := make(map[string]bool, len(nameWords))
+	for _, word := range nameWords {
+		inName[word] = true
+	}
+	shared := 0
+	for _, word := range queryWords {
+		if inName[word] {
+			shared++
+		}
+	}
+
+	// The same words in another order are close, but not certain
+	score := float64(shared) / float64(max(len(queryWords), len(nameWords)))
+	return min(score, 0.99)
+}
+
+// RankAnimes sorts search results by MatchConfidence against query, best first, keeping the site's order for ties.
+func RankAnimes(query string, animes []Anime) {
+	sort.SliceStable(animes, func(i, j int) bool {
+		return MatchConfidence(query, animes[i].Name) > MatchConfidence(query, animes[j].Name)
+	})
+}
+
+// AutoSelectAnime picks a search result without asking, according to an -auto-select mode:
+// util.AutoSelectExact takes the result only when exactly one name equals the query, util.AutoSelectTop takes
+// the best scored result, and util.AutoSelectOff never picks one. The boolean is false when the user has to choose.
+func AutoSelectAnime(query string, animes []Anime, mode string) (*Anime, bool) {
+	if mode == util.AutoSelectOff || len(animes) == 0 {
+		return nil, false
+	}
+
+	best, bestScore, exact := 0, -1.0, 0
+	for i, anime := range animes {
+		score := MatchConfidence(query, anime.Name)
+		if score == 1 {
+			exact++
+		}
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+
+	switch mode {
+	case util.AutoSelectTop:
+		return &animes[best], true
+	case util.AutoSelectExact:
+		if exact == 1 {
+			return &animes[best], true
+		}
+	}
+	return nil, false
+}
+
+// selectAnime lets AutoSelectAnime pick an obvious result and asks the user otherwise.
+func selectAnime(query string, animes []Anime) (*Anime, error) {
+	if anime, ok := AutoSelectAnime(query, animes, util.CurrentConfig().AutoSelect); ok {
+		fmt.Printf("Selected %s.\n", anime.Name)
+		return anime, nil
+	}
+	return animeSelector(animes)
+}
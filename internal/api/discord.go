@@ -47,7 +47,7 @@ func DiscordPresence(clientId string, anime Anime, isPaused bool, timestamp int6
 		return fmt.Errorf("failed to set Discord activity: %w", err)
 	}
 
-	if util.IsDebug {
+	if util.CurrentConfig().Debug {
 		log.Println("Discord Rich Presence updated successfully with cover image link and other details.")
 
 	}
@@ -1,28 +1,36 @@
 package api
 
 import (
+	"context"
 	"io"
 	"log"
+	"net/url"
+	"path"
 	"regexp"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/alvarorichard/Goanime/internal/util"
 	"github.com/pkg/errors"
 )
 
+// ErrNoEpisodes is returned when an anime page lists no episodes.
+var ErrNoEpisodes = errors.New("no episodes found")
+
 // GetAnimeEpisodes fetches and parses the list of episodes for a given anime.
-// It returns a sorted slice of Episode structs, ordered by episode number.
+// It returns a sorted slice of Episode structs, ordered by episode number, or ErrNoEpisodes if the page lists none.
 //
 // Parameters:
 // - animeURL: the URL of the anime's page.
 //
 // Returns:
 // - []Episode: a slice of Episode structs, sorted by episode number.
-// - error: an error if the process fails at any step.
+// - error: an error if the process fails at any step, or ErrNoEpisodes if no episodes were found.
 func GetAnimeEpisodes(animeURL string) ([]Episode, error) {
 	// Send an HTTP GET request to retrieve the anime details.
-	resp, err := SafeGet(animeURL)
+	resp, err := SafeGetContext(util.WithTrace(context.Background()), animeURL)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get anime details")
 	}
@@ -42,8 +50,11 @@ func GetAnimeEpisodes(animeURL string) ([]Episode, error) {
 
 	// Extract the episodes from the parsed HTML document.
 	episodes := parseEpisodes(doc)
+	if len(episodes) == 0 {
+		return nil, errors.Wrapf(ErrNoEpisodes, "no episodes listed at %s", animeURL)
+	}
 	// Sort the episodes by their numerical order.
-	sortEpisodesByNum(episodes)
+	SortEpisodes(episodes)
 
 	// Return the sorted list of episodes.
 	return episodes, nil
@@ -63,15 +74,18 @@ func parseEpisodes(doc *goquery.Document) []Episode {
 	// Find all anchor elements within the specified CSS selector that represent episodes and iterate over them.
 	doc.Find("a.lEp.epT.divNumEp.smallbox.px-2.mx-1.text-left.d-flex").Each(func(i int, s *goquery.Selection) {
 		// Extract the episode number (as text) and the href attribute (URL) from each element.
-		episodeNum := s.Text()
+		episodeNum := NormalizeText(s.Text())
 		episodeURL, _ := s.Attr("href")
 
-		// Parse the episode number as an integer.
+		// Parse the episode number as an integer, from the URL when the label has none.
 		num, err := parseEpisodeNumber(episodeNum)
 		if err != nil {
 			log.Printf("Error parsing episode number '%s': %v", episodeNum, err)
 			return
 		}
+		if urlNum, ok := EpisodeNumberFromURL(episodeURL); ok && !labelNumberRe.MatchString(episodeNum) {
+			num = urlNum
+		}
 
 		// Append the parsed episode information to the episodes slice.
 		episodes = append(episodes, Episode{
@@ -104,14 +118,80 @@ func parseEpisodeNumber(episodeNum string) (int, error) {
 	return strconv.Atoi(numStr)
 }
 
-// sortEpisodesByNum sorts a slice of Episode structs in ascending order by the episode number.
+// labelNumberRe matches episode labels that carry a number of their own.
+var labelNumberRe = regexp.MustCompile(`\d`)
+
+// urlEpisodeNumberRe matches the episode number at the end of the last segment of an episode URL path,
+// e.g. "/animes/naruto/1", "/naruto-episodio-01", "/naruto_1" or "/episode1", optionally followed by an extension.
+var urlEpisodeNumberRe = regexp.MustCompile(`(\d+)(?:\.[a-z]+)?$`)
+
+// EpisodeNumberFromURL returns the episode number at the end of an episode URL, ignoring zero padding.
+// The boolean is false when the URL doesn't end with a number.
+func EpisodeNumberFromURL(episodeURL string) (int, bool) {
+	urlPath := episodeURL
+	if parsed, err := url.Parse(episodeURL); err == nil {
+		urlPath = parsed.Path
+	}
+	segment := strings.ToLower(path.Base(strings.TrimRight(urlPath, "/")))
+
+	match := urlEpisodeNumberRe.FindStringSubmatch(segment)
+	if match == nil {
+		return 0, false
+	}
+	num, err := strconv.Atoi(match[1])
+	return num, err == nil
+}
+
+// episodeOrderRe matches the number used to order an episode label, including decimals such as "7.5".
+var episodeOrderRe = regexp.MustCompile(`\d+(?:[.,]\d+)?`)
+
+// episodeOrder returns the numeric position of an episode label, and false for labels without a number such as "OVA".
+func episodeOrder(number string) (float64, bool) {
+	match := episodeOrderRe.FindString(number)
+	if match == "" {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(strings.Replace(match, ",", ".", 1), 64)
+	return value, err == nil
+}
+
+// SortEpisodes sorts episodes by the number in their label, comparing numerically so "10" comes after "2"
+// and "7.5" sits between 7 and 8. Episodes without a number, such as specials labelled "OVA", go after the
+// numbered ones, and the sort is stable so equal episodes keep their listing order.
 //
 // Parameters:
-// - episodes: a slice of Episode structs to be sorted.
-func sortEpisodesByNum(episodes []Episode) {
-	// Sort the episodes slice in place using the sort.Slice function.
-	// The sorting is done based on the Num field of each Episode struct.
-	sort.Slice(episodes, func(i, j int) bool {
-		return episodes[i].Num < episodes[j].Num
+// - episodes: a slice of Episode structs to be sorted in place.
+func SortEpisodes(episodes []Episode) {
+	sort.SliceStable(episodes, func(i, j int) bool {
+		a, aNumbered := episodeOrder(episodes[i].Number)
+		b, bNumbered := episodeOrder(episodes[j].Number)
+		if aNumbered != bNumbered {
+			return aNumbered
+		}
+		return a < b
 	})
 }
+
+// LatestEpisode returns the index of the episode with the highest number. Listing order and labels aren't trusted,
+// since specials and re-uploads can appear anywhere in the list; if several episodes share the highest
+// number, the first one listed is returned.
+//
+// Parameters:
+// - episodes: the episodes of an anime.
+//
+// Returns:
+// - int: the index of the newest episode in episodes, or -1 with an error.
+// - error: ErrNoEpisodes if the list is empty.
+func LatestEpisode(episodes []Episode) (int, error) {
+	if len(episodes) == 0 {
+		return -1, ErrNoEpisodes
+	}
+
+	latest := 0
+	for i := range episodes {
+		if episodes[i].Num > episodes[latest].Num {
+			latest = i
+		}
+	}
+	return latest, nil
+}
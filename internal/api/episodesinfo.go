@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// EpisodeInfo is one line of -episodes-info output.
+type EpisodeInfo struct {
+	Number   int    `json:"number"`
+	Label    string `json:"label"`
+	Title    string `json:"title"`
+	Aired    string `json:"aired,omitempty"`
+	Duration int    `json:"duration"` // Seconds, 0 when unknown
+	Filler   bool   `json:"filler"`
+	Recap    bool   `json:"recap"`
+	URL      string `json:"url"`
+}
+
+// EpisodesInfo describes every episode, in the order given.
+// The title is the English one, falling back to the romaji one; both are only known after MergeEpisodeTitles.
+func EpisodesInfo(episodes []Episode) []EpisodeInfo {
+	infos := make([]EpisodeInfo, len(episodes))
+	for i, episode := range episodes {
+		title := episode.Title.English
+		if title == "" {
+			title = episode.Title.Romaji
+		}
+		infos[i] = EpisodeInfo{
+			Number:   episode.Num,
+			Label:    episode.Number,
+			Title:    title,
+			Aired:    episode.Aired,
+			Duration: episode.Duration,
+			Filler:   episode.IsFiller,
+			Recap:    episode.IsRecap,
+			URL:      episode.URL,
+		}
+	}
+	return infos
+}
+
+// WriteEpisodesInfo prints every episode with its number, title, duration and filler/recap flags,
+// as a JSON array with asJSON and as a table otherwise.
+//
+// Parameters:
+// - w: where the episodes are printed.
+// - episodes: the episodes to describe, with titles merged in when they are wanted.
+// - asJSON: print JSON instead of a table.
+//
+// Returns:
+// - error: an error if printing fails.
+func WriteEpisodesInfo(w io.Writer, episodes []Episode, asJSON bool) error {
+	infos := EpisodesInfo(episodes)
+
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(infos)
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "NUMBER\tTITLE\tDURATION\tFILLER\tRECAP")
+	for _, info := range infos {
+		fmt.Fprintf(table, "%d\t%s\t%s\t%s\t%s\n", info.Number, orDash(info.Title), formatDuration(info.Duration), yesNo(info.Filler), yesNo(info.Recap))
+	}
+	return table.Flush()
+}
+
+// formatDuration renders seconds as minutes and seconds, or "-" when unknown.
+func formatDuration(seconds int) string {
+	if seconds <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%dm%02ds", seconds/60, seconds%60)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
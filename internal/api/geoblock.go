@@ -0,0 +1,70 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrGeoBlocked is returned when a site refuses a request because of the user's region.
+var ErrGeoBlocked = errors.New("content is not available in your region")
+
+// geoBlockPeekSize is how much of a refused response is read to look for geo-block markers.
+const geoBlockPeekSize = 64 * 1024
+
+// geoBlockMarkers are phrases sites use on region-block pages, in English and Portuguese.
+var geoBlockMarkers = []string{
+	"not available in your region",
+	"not available in your country",
+	"blocked in your country",
+	"geo-restricted",
+	"geoblocked",
+	"não está disponível no seu país",
+	"não está disponível na sua região",
+	"indisponível na sua região",
+}
+
+// IsGeoBlocked reports whether a response with the given status and body is a region block.
+// 451 (Unavailable For Legal Reasons) always is; a 403 is only when its body carries a known marker,
+// so ordinary access-denied and Cloudflare pages aren't mistaken for one.
+func IsGeoBlocked(status int, body []byte) bool {
+	if status == http.StatusUnavailableForLegalReasons {
+		return true
+	}
+	if status != http.StatusForbidden {
+		return false
+	}
+	text := strings.ToLower(string(body))
+	for _, marker := range geoBlockMarkers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkGeoBlock turns a region-blocked response into ErrGeoBlocked.
+// Any other response is returned unchanged, with the part of the body read for the check put back.
+func checkGeoBlock(req *http.Request, resp *http.Response) (*http.Response, error) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusUnavailableForLegalReasons {
+		return resp, nil
+	}
+
+	peek, err := io.ReadAll(io.LimitReader(resp.Body, geoBlockPeekSize))
+	if err != nil {
+		return resp, nil
+	}
+	if IsGeoBlocked(resp.StatusCode, peek) {
+		_ = resp.Body.Close()
+		return nil, errors.Wrapf(ErrGeoBlocked, "%s refused the request, try again through a VPN in another country", req.URL.Host)
+	}
+
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peek), resp.Body), resp.Body}
+	return resp, nil
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// defaultHostConcurrency is the number of parallel download connections allowed per host unless overridden.
+// AnimeFire and Blogger start answering with block pages when hit by many parallel requests.
+var defaultHostConcurrency = map[string]int{
+	"animefire.plus": 2,
+	"blogger.com":    2,
+}
+
+// fallbackHostConcurrency applies to hosts without their own limit.
+const fallbackHostConcurrency = 4
+
+// HostLimiter caps the number of concurrent operations per host with one semaphore per host.
+// A limit set for a domain also covers its subdomains.
+type HostLimiter struct {
+	limits   map[string]int
+	fallback int
+	mu       sync.Mutex
+	slots    map[string]chan struct{}
+}
+
+// NewHostLimiter creates a HostLimiter with per-host limits and a fallback for every other host.
+func NewHostLimiter(limits map[string]int, fallback int) *HostLimiter {
+	normalized := make(map[string]int, len(limits))
+	for host, limit := range limits {
+		normalized[strings.ToLower(host)] = limit
+	}
+	return &HostLimiter{limits: normalized, fallback: fallback, slots: make(map[string]chan struct{})}
+}
+
+// limitFor returns the configured domain matching host and its limit.
+func (l *HostLimiter) limitFor(host string) (string, int) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for domain, limit := range l.limits {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return domain, limit
+		}
+	}
+	return host, l.fallback
+}
+
+// Acquire blocks until an operation against the host of rawURL may start and returns the function releasing its slot.
+func (l *HostLimiter) Acquire(rawURL string) func() {
+	host := rawURL
+	if parsed, err := url.Parse(rawURL); err == nil && parsed.Host != "" {
+		host = parsed.Hostname()
+	}
+	key, limit := l.limitFor(host)
+	if limit <= 0 {
+		return func() {}
+	}
+
+	l.mu.Lock()
+	slot, ok := l.slots[key]
+	if !ok {
+		slot = make(chan struct{}, limit)
+		l.slots[key] = slot
+	}
+	l.mu.Unlock()
+
+	slot <- struct{}{}
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-slot })
+	}
+}
+
+// Transport wraps next so that every request waits for a slot for its host, held until the response body is closed.
+// A download split into ranges then counts each range request against the limit, not just the episode.
+func (l *HostLimiter) Transport(next http.RoundTripper) http.RoundTripper {
+	return &hostLimitedTransport{limiter: l, next: next}
+}
+
+type hostLimitedTransport struct {
+	limiter *HostLimiter
+	next    http.RoundTripper
+}
+
+func (t *hostLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	release := t.limiter.Acquire(req.URL.String())
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releasingBody frees the host slot of its request once closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
+
+// sourceLimiter is shared by every download of the session.
+var sourceLimiter = NewHostLimiter(defaultHostConcurrency, fallbackHostConcurrency)
+
+// SetHostConcurrency overrides the per-host limits used by AcquireHost, keeping the defaults for hosts not listed.
+func SetHostConcurrency(limits map[string]int) {
+	merged := make(map[string]int, len(defaultHostConcurrency)+len(limits))
+	for host, limit := range defaultHostConcurrency {
+		merged[host] = limit
+	}
+	for host, limit := range limits {
+		merged[host] = limit
+	}
+	sourceLimiter = NewHostLimiter(merged, fallbackHostConcurrency)
+}
+
+// AcquireHost waits for a free slot for the host of rawURL and returns the function releasing it.
+func AcquireHost(rawURL string) func() {
+	return sourceLimiter.Acquire(rawURL)
+}
+
+// HostLimitedTransport wraps next so that its requests share the per-host limits of AcquireHost.
+func HostLimitedTransport(next http.RoundTripper) http.RoundTripper {
+	return sourceLimiter.Transport(next)
+}
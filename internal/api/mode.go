@@ -0,0 +1,39 @@
+package api
+
+import (
+	"log"
+	"strings"
+
+	"github.com/alvarorichard/Goanime/internal/util"
+)
+
+// IsDubbed reports whether a search result is a dubbed version. AnimeFire has no separate dub track: dubs are
+// listed as their own anime, named "... (Dublado)" with a "-dublado" URL.
+func IsDubbed(anime Anime) bool {
+	return strings.Contains(strings.ToLower(anime.Name), "dublado") || strings.Contains(strings.ToLower(anime.URL), "-dublado")
+}
+
+// FilterByMode keeps the search results in the audio mode chosen with -mode, keeping their order.
+// An empty mode keeps every result. When no result is in the mode, every result is kept and the user is told,
+// so a show without a dub can still be watched subtitled.
+func FilterByMode(animes []Anime, mode string) []Anime {
+	if mode != util.ModeSub && mode != util.ModeDub {
+		return animes
+	}
+
+	var kept []Anime
+	for _, anime := range animes {
+		if IsDubbed(anime) == (mode == util.ModeDub) {
+			kept = append(kept, anime)
+		}
+	}
+	if len(kept) == 0 {
+		if mode == util.ModeDub {
+			log.Println("No dubbed version found, listing the subtitled results instead")
+		} else {
+			log.Println("No subtitled version found, listing the dubbed results instead")
+		}
+		return animes
+	}
+	return kept
+}
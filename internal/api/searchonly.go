@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// SourceName is how GoAnime names the site it scrapes in its output.
+const SourceName = "AnimeFire"
+
+// SearchResult is one line of -search-only output.
+type SearchResult struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	URL    string `json:"url"`
+}
+
+// SearchOnly searches for animeName like SearchAnime, but prints every result, best match first, instead of
+// selecting one. Episode lists are not fetched, so it costs a single search. With asJSON the results are printed
+// as a JSON array, otherwise as a table.
+//
+// Parameters:
+// - w: where the results are printed.
+// - animeName: the search slug, e.g. "one-piece".
+// - asJSON: print JSON instead of a table.
+//
+// Returns:
+// - error: ErrNoAnimeFound, or an error if the search or printing fails.
+func SearchOnly(w io.Writer, animeName string, asJSON bool) error {
+	query, animes, err := searchWithFallback(animeName)
+	if err != nil {
+		return err
+	}
+	RankAnimes(query, animes)
+
+	results := make([]SearchResult, len(animes))
+	for i, anime := range animes {
+		results[i] = SearchResult{Name: anime.Name, Source: SourceName, URL: anime.URL}
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "NAME\tSOURCE\tURL")
+	for _, result := range results {
+		fmt.Fprintf(table, "%s\t%s\t%s\n", result.Name, result.Source, result.URL)
+	}
+	return table.Flush()
+}
@@ -1,13 +1,18 @@
 package api
 
+import (
+	"net/url"
+	"strings"
+)
+
 // IsSeries checks if the given anime URL corresponds to a series (multiple episodes).
-// It returns a boolean indicating if the anime has more than one episode, the total number of episodes, and an error if any issues occur.
+// It returns a boolean indicating if the anime is a series, the total number of episodes, and an error if any issues occur.
 //
 // Parameters:
 // - animeURL: the URL of the anime's page.
 //
 // Returns:
-// - bool: true if the anime has more than one episode (i.e., is a series), false otherwise.
+// - bool: true if the anime is a series, false if it is a movie/OVA.
 // - int: the total number of episodes found.
 // - error: an error if the process of retrieving episodes fails.
 func IsSeries(animeURL string) (bool, int, error) {
@@ -18,6 +23,32 @@ func IsSeries(animeURL string) (bool, int, error) {
 		return false, 0, err
 	}
 
-	// Return true if the anime has more than one episode, along with the episode count.
-	return len(episodes) > 1, len(episodes), nil
+	return IsSeriesFromEpisodes(animeURL, episodes), len(episodes), nil
+}
+
+// IsSeriesFromEpisodes classifies an anime from an episode list that was already fetched.
+// More than one episode always means a series. A single episode is ambiguous: it is a movie when the
+// AnimeFire URL points to a film ("/filmes/" or a "filme"/"movie" slug), and a series when the slug is
+// AnimeFire's "todos-os-episodios" listing, as happens with a show that has only aired its first episode.
+//
+// Parameters:
+// - animeURL: the URL of the anime's page.
+// - episodes: the episodes listed on that page.
+//
+// Returns:
+// - bool: true if the anime is a series, false if it is a movie/OVA.
+func IsSeriesFromEpisodes(animeURL string, episodes []Episode) bool {
+	if len(episodes) != 1 {
+		return len(episodes) > 1
+	}
+
+	path := strings.ToLower(animeURL)
+	if parsed, err := url.Parse(animeURL); err == nil {
+		path = strings.ToLower(parsed.Path)
+	}
+
+	if strings.Contains(path, "filme") || strings.Contains(path, "movie") {
+		return false
+	}
+	return strings.Contains(path, "todos-os-episodios")
 }
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// cp1252Bytes maps the characters Windows-1252 puts in the 0x80-0x9F range back to their byte,
+// so that UTF-8 text that was decoded as Windows-1252 rather than Latin-1 can be repaired too.
+var cp1252Bytes = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91,
+	'’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98,
+	'™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// NormalizeText repairs a scraped anime or episode title for display; the parsers run every title through it.
+// Bytes that are not valid UTF-8 are read as Latin-1, UTF-8 that was mis-decoded as Latin-1 or Windows-1252
+// (mojibake such as "AtÃ© o Fim" for "Até o Fim") is decoded again, control characters are dropped and runs
+// of whitespace are collapsed to a single space.
+func NormalizeText(title string) string {
+	title = decodeLatin1Bytes(title)
+	// Text can be mis-decoded more than once on its way through a site's pipeline
+	for i := 0; i < 2; i++ {
+		fixed, ok := fixMojibake(title)
+		if !ok {
+			break
+		}
+		title = fixed
+	}
+
+	title = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, title)
+	return strings.Join(strings.Fields(title), " ")
+}
+
+// decodeLatin1Bytes reads every byte of s that is not part of valid UTF-8 as a Latin-1 character.
+func decodeLatin1Bytes(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			r = rune(s[i])
+		}
+		b.WriteRune(r)
+		i += size
+	}
+	return b.String()
+}
+
+// fixMojibake undoes one round of UTF-8 being decoded as Latin-1 or Windows-1252.
+// It only succeeds when every character maps back to a single byte and those bytes form valid UTF-8
+// with at least one multi-byte character, which text that was never mis-decoded practically never does.
+func fixMojibake(s string) (string, bool) {
+	raw := make([]byte, 0, len(s))
+	multiByte := false
+	for _, r := range s {
+		switch b, ok := cp1252Bytes[r]; {
+		case ok:
+			raw = append(raw, b)
+		case r <= 0xFF:
+			raw = append(raw, byte(r))
+		default:
+			return "", false
+		}
+		if r >= 0x80 {
+			multiByte = true
+		}
+	}
+
+	if !multiByte || !utf8.Valid(raw) {
+		return "", false
+	}
+	return string(raw), true
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/pkg/errors"
+)
+
+// EpisodeDetails holds the per-episode metadata returned by the Jikan episodes listing.
+type EpisodeDetails struct {
+	Num      int    `json:"mal_id"`
+	Title    string `json:"title"`
+	Romaji   string `json:"title_romanji"`
+	Japanese string `json:"title_japanese"`
+	Aired    string `json:"aired"`
+	Filler   bool   `json:"filler"`
+	Recap    bool   `json:"recap"`
+}
+
+// jikanEpisodesResponse is one page of the Jikan /anime/{id}/episodes listing.
+type jikanEpisodesResponse struct {
+	Data       []EpisodeDetails `json:"data"`
+	Pagination struct {
+		HasNextPage bool `json:"has_next_page"`
+	} `json:"pagination"`
+}
+
+// jikanPageDelay keeps the paged listing under Jikan's rate limit of three requests per second.
+const jikanPageDelay = 350 * time.Millisecond
+
+var (
+	episodeTitlesMu    sync.Mutex
+	episodeTitlesCache = map[int][]EpisodeDetails{}
+)
+
+// FetchEpisodeTitles returns the titles and air dates of every episode of the anime with the given MAL ID.
+// Results are cached per anime for the rest of the run.
+func FetchEpisodeTitles(malID int) ([]EpisodeDetails, error) {
+	if malID == 0 {
+		return nil, fmt.Errorf("anime has no MAL ID to fetch episode titles with")
+	}
+
+	episodeTitlesMu.Lock()
+	cached, ok := episodeTitlesCache[malID]
+	episodeTitlesMu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	var details []EpisodeDetails
+	for page := 1; ; page++ {
+		if page > 1 {
+			time.Sleep(jikanPageDelay)
+		}
+
+		pageData, err := fetchEpisodeTitlesPage(malID, page)
+		if err != nil {
+			return nil, err
+		}
+		details = append(details, pageData.Data...)
+
+		if !pageData.Pagination.HasNextPage {
+			break
+		}
+	}
+
+	if util.CurrentConfig().Debug {
+		log.Printf("Fetched %d episode titles from Jikan for MAL ID %d", len(details), malID)
+	}
+
+	episodeTitlesMu.Lock()
+	episodeTitlesCache[malID] = details
+	episodeTitlesMu.Unlock()
+
+	return details, nil
+}
+
+// fetchEpisodeTitlesPage fetches a single page of the Jikan episodes listing.
+func fetchEpisodeTitlesPage(malID, page int) (*jikanEpisodesResponse, error) {
+	url := fmt.Sprintf("https://api.jikan.moe/v4/anime/%d/episodes?page=%d", malID, page)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GET request: %w", err)
+	}
+	decorateRequest(req)
+
+	resp, err := doerOr(&http.Client{Timeout: 10 * time.Second}).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching episode titles from Jikan (MyAnimeList) API: %w", err)
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			log.Printf("Failed to close response body: %v", err)
+		}
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Jikan episodes request failed with status %d", resp.StatusCode)
+	}
+
+	var result jikanEpisodesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse Jikan episodes response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// MergeEpisodeTitles copies titles, air dates and filler/recap flags onto the episodes with the matching number.
+// Episodes without a matching entry are left untouched.
+func MergeEpisodeTitles(episodes []Episode, details []EpisodeDetails) {
+	byNum := make(map[int]EpisodeDetails, len(details))
+	for _, d := range details {
+		byNum[d.Num] = d
+	}
+
+	for i := range episodes {
+		d, ok := byNum[episodes[i].Num]
+		if !ok {
+			continue
+		}
+		episodes[i].Title = TitleDetails{
+			Romaji:   NormalizeText(d.Romaji),
+			English:  NormalizeText(d.Title),
+			Japanese: NormalizeText(d.Japanese),
+		}
+		episodes[i].Aired = d.Aired
+		episodes[i].IsFiller = d.Filler
+		episodes[i].IsRecap = d.Recap
+	}
+}
+
+// ParseAirDate reads an air date as Jikan gives it, e.g. "2025-01-12T00:00:00+00:00", or a plain "2025-01-12".
+// The boolean is false for episodes without a date.
+func ParseAirDate(aired string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if date, err := time.Parse(layout, aired); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// EpisodesAiredSince returns the episodes that aired on or after since, keeping their order.
+// Air dates are only known after MergeEpisodeTitles; episodes without one are dropped, or kept with keepUndated.
+func EpisodesAiredSince(episodes []Episode, since time.Time, keepUndated bool) []Episode {
+	var recent []Episode
+	for _, episode := range episodes {
+		aired, ok := ParseAirDate(episode.Aired)
+		if (!ok && keepUndated) || (ok && !aired.Before(since)) {
+			recent = append(recent, episode)
+		}
+	}
+	return recent
+}
+
+// maxTitleSuggestions is how many candidates FindEpisodeByTitle lists when a query is ambiguous.
+const maxTitleSuggestions = 5
+
+// FindEpisodeByTitle returns the index of the episode whose title matches query, ignoring case.
+// An episode matches when every word of the query appears in one of its titles; an exact title match wins over partial ones.
+// Titles are only known after MergeEpisodeTitles, so episodes without titles never match.
+//
+// Parameters:
+// - episodes: the episodes to search, with titles merged in.
+// - query: the title or part of it, e.g. "finale".
+//
+// Returns:
+// - int: the index of the matching episode in episodes, or -1 with an error.
+// - error: an error if no episode matches, or one listing the closest matches if several do.
+func FindEpisodeByTitle(episodes []Episode, query string) (int, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	words := strings.Fields(query)
+	if len(words) == 0 {
+		return -1, errors.New("episode title must not be empty")
+	}
+
+	var matches []int
+	for i, episode := range episodes {
+		for _, title := range []string{episode.Title.English, episode.Title.Romaji, episode.Title.Japanese} {
+			title = strings.ToLower(title)
+			if title == "" {
+				continue
+			}
+			if title == query {
+				return i, nil
+			}
+			if containsAllWords(title, words) {
+				matches = append(matches, i)
+				break
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return -1, errors.Errorf("no episode title matches %q", query)
+	case 1:
+		return matches[0], nil
+	}
+
+	suggestions := make([]string, 0, maxTitleSuggestions)
+	for _, i := range matches {
+		if len(suggestions) == maxTitleSuggestions {
+			break
+		}
+		suggestions = append(suggestions, fmt.Sprintf("%d: %s", episodes[i].Num, episodes[i].Title.English))
+	}
+	return -1, errors.Errorf("%d episodes match %q, be more specific: %s", len(matches), query, strings.Join(suggestions, "; "))
+}
+
+// containsAllWords reports whether every word appears in s.
+func containsAllWords(s string, words []string) bool {
+	for _, word := range words {
+		if !strings.Contains(s, word) {
+			return false
+		}
+	}
+	return true
+}
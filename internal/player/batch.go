@@ -0,0 +1,85 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/manifoldco/promptui"
+)
+
+// ErrTooManyEpisodes is returned when a batch download is larger than -max-episodes and wasn't confirmed.
+var ErrTooManyEpisodes = errors.New("too many episodes requested")
+
+// CountEpisodesInRange returns how many of the listed episodes are numbered from startNum to endNum.
+func CountEpisodesInRange(episodes []api.Episode, startNum, endNum int) int {
+	count := 0
+	for _, ep := range episodes {
+		if ep.Num >= startNum && ep.Num <= endNum {
+			count++
+		}
+	}
+	return count
+}
+
+// MissingEpisodes returns the numbers from startNum to endNum that no listed episode has, in order.
+func MissingEpisodes(episodes []api.Episode, startNum, endNum int) []int {
+	listed := make(map[int]bool, len(episodes))
+	for _, ep := range episodes {
+		listed[ep.Num] = true
+	}
+
+	var missing []int
+	for num := startNum; num <= endNum; num++ {
+		if !listed[num] {
+			missing = append(missing, num)
+		}
+	}
+	return missing
+}
+
+// FormatEpisodeRanges joins sorted episode numbers, collapsing runs, e.g. "13, 25-30".
+func FormatEpisodeRanges(nums []int) string {
+	var parts []string
+	for i := 0; i < len(nums); {
+		j := i
+		for j+1 < len(nums) && nums[j+1] == nums[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, strconv.Itoa(nums[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", nums[i], nums[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ConfirmBatchSize guards against queueing a huge batch by mistake, such as typing 1-1000 instead of 1-10.
+// A batch of more than -max-episodes episodes needs -yes or a confirmation; confirm is nil when nobody can
+// be asked, as with -only-new, and the batch is then refused.
+func ConfirmBatchSize(count int, confirm func(count int) bool) error {
+	config := util.CurrentConfig()
+	if config.MaxEpisodes <= 0 || count <= config.MaxEpisodes || config.Yes {
+		return nil
+	}
+	if confirm != nil && confirm(count) {
+		return nil
+	}
+	return fmt.Errorf("%w: %d episodes is more than -max-episodes %d; pass -yes to download them anyway",
+		ErrTooManyEpisodes, count, config.MaxEpisodes)
+}
+
+// askToConfirmBatch asks the user whether to go on with a batch of count episodes.
+func askToConfirmBatch(count int) bool {
+	prompt := promptui.Prompt{
+		Label:     fmt.Sprintf("This will download %d episodes. Continue", count),
+		IsConfirm: true,
+	}
+	result, err := prompt.Run()
+	return err == nil && strings.EqualFold(result, "y")
+}
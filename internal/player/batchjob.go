@@ -0,0 +1,139 @@
+package player
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/alvarorichard/Goanime/internal/util"
+)
+
+// ErrNoBatchJob is returned by LoadBatchJob when no interrupted batch download was saved.
+var ErrNoBatchJob = errors.New("no interrupted batch download to resume")
+
+// BatchJob is the saved state of a batch download, so -resume-batch can finish it after an interruption.
+// Only the latest batch download is kept.
+type BatchJob struct {
+	AnimeURL  string `json:"anime_url"`
+	Start     int    `json:"start"`
+	End       int    `json:"end"`
+	Completed []int  `json:"completed"` // Downloaded, or not offered by the site at all
+
+	path string
+	mu   sync.Mutex
+}
+
+// BatchJobPath returns where the batch download state is saved.
+func BatchJobPath() (string, error) {
+	dataDir, err := util.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "batch.json"), nil
+}
+
+// NewBatchJob creates the state of a batch download of episodes start to end, saved to path.
+func NewBatchJob(path, animeURL string, start, end int) *BatchJob {
+	return &BatchJob{AnimeURL: animeURL, Start: start, End: end, path: path}
+}
+
+// LoadBatchJob reads the batch download state saved at path, or returns ErrNoBatchJob when there is none.
+func LoadBatchJob(path string) (*BatchJob, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNoBatchJob
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	job := &BatchJob{path: path}
+	if err := json.Unmarshal(data, job); err != nil {
+		return nil, fmt.Errorf("failed to read the batch download state %s: %w", path, err)
+	}
+	return job, nil
+}
+
+// Save writes the state to its file, replacing it atomically so an interruption never leaves half a file.
+func (j *BatchJob) Save() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.save()
+}
+
+func (j *BatchJob) save() error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), os.ModePerm); err != nil {
+		return err
+	}
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, j.path)
+}
+
+// MarkCompleted records that an episode was downloaded and saves the state.
+func (j *BatchJob) MarkCompleted(num int) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, done := range j.Completed {
+		if done == num {
+			return nil
+		}
+	}
+	j.Completed = append(j.Completed, num)
+	sort.Ints(j.Completed)
+	return j.save()
+}
+
+// IsCompleted reports whether the episode was already downloaded by this job.
+func (j *BatchJob) IsCompleted(num int) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, done := range j.Completed {
+		if done == num {
+			return true
+		}
+	}
+	return false
+}
+
+// Remaining returns the episode numbers of the range that are not downloaded yet, in order.
+func (j *BatchJob) Remaining() []int {
+	var remaining []int
+	for num := j.Start; num <= j.End; num++ {
+		if !j.IsCompleted(num) {
+			remaining = append(remaining, num)
+		}
+	}
+	return remaining
+}
+
+// Finish removes the saved state once every episode of the job is downloaded.
+func (j *BatchJob) Finish() error {
+	if len(j.Remaining()) > 0 {
+		return nil
+	}
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// recordBatchProgress marks an episode of the job as downloaded, logging instead of failing the download.
+func recordBatchProgress(job *BatchJob, num int) {
+	if job == nil {
+		return
+	}
+	if err := job.MarkCompleted(num); err != nil {
+		fmt.Printf("Failed to save the batch download progress: %v\n", err)
+	}
+}
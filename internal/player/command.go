@@ -0,0 +1,92 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/alvarorichard/Goanime/internal/util"
+)
+
+// redactedHost replaces the host of stream URLs when -redact is set.
+const redactedHost = "REDACTED"
+
+// MPVArgs builds the argument list used to start mpv with an IPC socket.
+// With -mpv-profile isolated, mpv also gets --no-config so the user's mpv.conf, input.conf and scripts are ignored;
+// everything GoAnime relies on is passed on the command line either way.
+// Subtitle styling set with -sub-scale, -sub-color and -sub-pos is passed on as mpv's options of the same name.
+func MPVArgs(link, socketPath string, args []string) []string {
+	config := util.CurrentConfig()
+	base := []string{"--no-terminal", "--quiet", fmt.Sprintf("--input-ipc-server=%s", socketPath), link}
+	if config.MPVProfile == util.MPVProfileIsolated {
+		base = append([]string{"--no-config"}, base...)
+	}
+	if config.SubScale > 0 && config.SubScale != util.DefaultSubScale {
+		base = append(base, "--sub-scale="+strconv.FormatFloat(config.SubScale, 'f', -1, 64))
+	}
+	if config.SubColor != "" {
+		base = append(base, "--sub-color="+config.SubColor)
+	}
+	if config.SubPos != util.DefaultSubPos {
+		base = append(base, fmt.Sprintf("--sub-pos=%d", config.SubPos))
+	}
+	return append(base, args...)
+}
+
+// FormatCommand renders a command and its arguments as a single shell-friendly line.
+// Arguments containing spaces or shell metacharacters are single-quoted so the line can be pasted as-is.
+// When redact is true, the host of every http(s) URL argument is replaced.
+func FormatCommand(name string, args []string, redact bool) string {
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, shellQuote(name))
+	for _, arg := range args {
+		if redact {
+			arg = redactURLHost(arg)
+		}
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// newCommand creates the command to run, printing it first when -print-command is set.
+func newCommand(name string, args ...string) *exec.Cmd {
+	printCommand(name, args)
+	return exec.Command(name, args...)
+}
+
+// newCommandContext is newCommand for a command that is killed when ctx is done.
+func newCommandContext(ctx context.Context, name string, args ...string) *exec.Cmd {
+	printCommand(name, args)
+	return exec.CommandContext(ctx, name, args...)
+}
+
+// printCommand prints the command about to run when -print-command is set.
+func printCommand(name string, args []string) {
+	if util.CurrentConfig().PrintCommand {
+		fmt.Println("Running:", FormatCommand(name, args, util.CurrentConfig().RedactCommand))
+	}
+}
+
+// shellQuote wraps s in single quotes when it contains characters a shell would interpret.
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if !strings.ContainsAny(s, " \t\n'\"\\$`&|;<>()*?[]#~!{}") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// redactURLHost hides the host of an http(s) URL, leaving any other argument untouched.
+func redactURLHost(arg string) string {
+	u, err := url.Parse(arg)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return arg
+	}
+	u.Host = redactedHost
+	return u.String()
+}
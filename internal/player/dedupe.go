@@ -0,0 +1,262 @@
+package player
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/alvarorichard/Goanime/internal/util"
+)
+
+// DedupeEntry records one downloaded file in the -dedupe index.
+type DedupeEntry struct {
+	Path   string `json:"path"`
+	Hash   string `json:"hash"` // SHA-256 of the file contents
+	Size   int64  `json:"size"`
+	Source string `json:"source"` // The video URL it was downloaded from, see DedupeSource
+}
+
+// DedupeIndex maps downloaded files to their content hashes, so a byte-identical episode saved under
+// another anime folder is hardlinked instead of being stored twice.
+type DedupeIndex struct {
+	path    string
+	mu      sync.Mutex
+	Entries []DedupeEntry `json:"entries"`
+}
+
+// LoadDedupeIndex reads the index stored at path. A missing file gives an empty index.
+func LoadDedupeIndex(path string) (*DedupeIndex, error) {
+	index := &DedupeIndex{path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("failed to parse dedupe index %s: %w", path, err)
+	}
+	return index, nil
+}
+
+// save writes the index back to its file. The caller must hold mu.
+func (idx *DedupeIndex) save() error {
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(idx.path), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(idx.path, data, 0644)
+}
+
+// present returns the first entry matching keep whose file still exists with the recorded size.
+func (idx *DedupeIndex) present(keep func(DedupeEntry) bool) (DedupeEntry, bool) {
+	for _, entry := range idx.Entries {
+		if !keep(entry) {
+			continue
+		}
+		if info, err := os.Stat(entry.Path); err == nil && info.Size() == entry.Size {
+			return entry, true
+		}
+	}
+	return DedupeEntry{}, false
+}
+
+// LinkFromSource links destPath to a file already downloaded from the same source, so it doesn't have to be downloaded again.
+// It returns false when no such file is known or the link can't be made.
+func (idx *DedupeIndex) LinkFromSource(source, destPath string) (bool, error) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if source == "" {
+		return false, nil
+	}
+	existing, ok := idx.present(func(e DedupeEntry) bool { return e.Source == source && e.Path != destPath })
+	if !ok {
+		return false, nil
+	}
+	if err := linkFile(existing.Path, destPath); err != nil {
+		return false, err
+	}
+	idx.put(DedupeEntry{Path: destPath, Hash: existing.Hash, Size: existing.Size, Source: source})
+	return true, idx.save()
+}
+
+// put adds entry to the index, replacing the entry recorded earlier for the same path. The caller must hold mu.
+func (idx *DedupeIndex) put(entry DedupeEntry) {
+	for i := range idx.Entries {
+		if idx.Entries[i].Path == entry.Path {
+			idx.Entries[i] = entry
+			return
+		}
+	}
+	idx.Entries = append(idx.Entries, entry)
+}
+
+// Record hashes a finished download and adds it to the index, replacing any earlier entry for path.
+// When a byte-identical file is already indexed elsewhere, path is replaced with a hardlink to it and true is returned.
+// The link is made next to path and renamed over it, so the download is kept when the link can't be made.
+func (idx *DedupeIndex) Record(path, source string) (bool, error) {
+	hash, size, err := HashFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	linked := false
+	var linkErr error
+	existing, ok := idx.present(func(e DedupeEntry) bool { return e.Hash == hash && e.Path != path })
+	if ok && !sameFile(existing.Path, path) {
+		if linkErr = replaceWithLink(existing.Path, path); linkErr == nil {
+			linked = true
+		}
+	}
+
+	idx.put(DedupeEntry{Path: path, Hash: hash, Size: size, Source: source})
+	if err := idx.save(); err != nil {
+		return linked, err
+	}
+	return linked, linkErr
+}
+
+// replaceWithLink replaces path with a link to existing. The link is created as path+".tmp" and renamed over path,
+// so path is left untouched if either step fails.
+func replaceWithLink(existing, path string) error {
+	tmp := path + ".tmp"
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := linkFile(existing, tmp); err != nil {
+		return fmt.Errorf("failed to link %s to %s: %w", path, existing, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to replace %s with a link: %w", path, err)
+	}
+	return nil
+}
+
+// HashFile returns the hex SHA-256 and the size of the file at path.
+func HashFile(path string) (string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer func(file *os.File) {
+		err := file.Close()
+		if err != nil {
+			log.Printf("Failed to close file: %v\n", err)
+		}
+	}(file)
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
+
+// DedupeSource returns the part of a video URL that identifies its contents.
+// The query of direct file URLs only carries expiring access tokens and is dropped;
+// for other URLs, such as Blogger's video.g?token=..., the query is what identifies the video and is kept.
+func DedupeSource(videoURL string) string {
+	parsed, err := url.Parse(videoURL)
+	if err != nil {
+		return videoURL
+	}
+	if ext := strings.ToLower(filepath.Ext(parsed.Path)); ext == ".mp4" || ext == ".m3u8" || ext == ".mkv" {
+		parsed.RawQuery = ""
+		parsed.Fragment = ""
+	}
+	return parsed.String()
+}
+
+// linkFile creates destPath as a hardlink to existing, falling back to a symlink across filesystems.
+func linkFile(existing, destPath string) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), os.ModePerm); err != nil {
+		return err
+	}
+	if err := os.Link(existing, destPath); err == nil {
+		return nil
+	}
+	return os.Symlink(existing, destPath)
+}
+
+// sameFile reports whether both paths already point to the same file.
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+var (
+	sessionDedupeOnce  sync.Once
+	sessionDedupeIndex *DedupeIndex
+)
+
+// dedupeIndex returns the index shared by the session, or nil when -dedupe is off or the index can't be read.
+func dedupeIndex() *DedupeIndex {
+	sessionDedupeOnce.Do(func() {
+		if !util.CurrentConfig().Dedupe {
+			return
+		}
+		root, err := util.DownloadsDir()
+		if err != nil {
+			log.Println("Failed to find the downloads folder for -dedupe:", err)
+			return
+		}
+		index, err := LoadDedupeIndex(filepath.Join(root, "dedupe.json"))
+		if err != nil {
+			log.Println("Failed to load the dedupe index:", err)
+			return
+		}
+		sessionDedupeIndex = index
+	})
+	return sessionDedupeIndex
+}
+
+// linkKnownDownload links episodePath to an earlier download of the same video when -dedupe is set.
+// Once linked, the episode is treated as already downloaded.
+func linkKnownDownload(videoURL, episodePath string) {
+	index := dedupeIndex()
+	if index == nil {
+		return
+	}
+	if _, err := os.Stat(episodePath); err == nil {
+		return
+	}
+	linked, err := index.LinkFromSource(DedupeSource(videoURL), episodePath)
+	if err != nil {
+		log.Printf("Failed to link %s to an earlier download: %v\n", episodePath, err)
+	} else if linked {
+		fmt.Printf("Linked %s to an identical earlier download.\n", episodePath)
+	}
+}
+
+// recordDownload adds a finished download to the -dedupe index, replacing it with a hardlink if an identical file exists.
+func recordDownload(videoURL, episodePath string) {
+	index := dedupeIndex()
+	if index == nil {
+		return
+	}
+	linked, err := index.Record(episodePath, DedupeSource(videoURL))
+	if err != nil {
+		log.Printf("Failed to record %s in the dedupe index: %v\n", episodePath, err)
+	} else if linked {
+		fmt.Printf("%s is identical to an earlier download and now shares its disk space.\n", episodePath)
+	}
+}
@@ -0,0 +1,85 @@
+package player
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Downloader is a way of downloading an episode's video.
+type Downloader int
+
+const (
+	// DownloaderHTTP is GoAnime's own multi-threaded HTTP download.
+	DownloaderHTTP Downloader = iota
+	// DownloaderYtDlp hands the download over to yt-dlp.
+	DownloaderYtDlp
+)
+
+// sourceDownloaders declares the downloader each video source needs, whatever its URLs look like.
+// Blogger only hands out the video after yt-dlp has gone through its player page.
+// A domain also covers its subdomains.
+var (
+	sourceDownloadersMu sync.Mutex
+	sourceDownloaders   = map[string]Downloader{
+		"blogger.com": DownloaderYtDlp,
+	}
+)
+
+// SetSourceDownloader declares the downloader videos from host must use and returns the function restoring the previous declaration.
+func SetSourceDownloader(host string, downloader Downloader) func() {
+	host = strings.ToLower(host)
+
+	sourceDownloadersMu.Lock()
+	defer sourceDownloadersMu.Unlock()
+	previous, existed := sourceDownloaders[host]
+	sourceDownloaders[host] = downloader
+
+	return func() {
+		sourceDownloadersMu.Lock()
+		defer sourceDownloadersMu.Unlock()
+		if existed {
+			sourceDownloaders[host] = previous
+		} else {
+			delete(sourceDownloaders, host)
+		}
+	}
+}
+
+// PreferredDownloader returns the downloader for videoURL.
+// The downloader declared by the video's source wins; for other sources HLS playlists, known by their URL or by the
+// container the site listed them with, go to yt-dlp and everything else is downloaded over HTTP.
+func PreferredDownloader(videoURL string) Downloader {
+	host, path := videoURL, videoURL
+	if parsed, err := url.Parse(videoURL); err == nil && parsed.Host != "" {
+		host, path = parsed.Hostname(), parsed.Path
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	sourceDownloadersMu.Lock()
+	for domain, downloader := range sourceDownloaders {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			sourceDownloadersMu.Unlock()
+			return downloader
+		}
+	}
+	sourceDownloadersMu.Unlock()
+
+	if isHLSPath(path) || resolvedAsHLS(videoURL) {
+		return DownloaderYtDlp
+	}
+	return DownloaderHTTP
+}
+
+// isHLSPlaylist reports whether videoURL points to an HLS playlist rather than a progressive video file.
+func isHLSPlaylist(videoURL string) bool {
+	path := videoURL
+	if parsed, err := url.Parse(videoURL); err == nil {
+		path = parsed.Path
+	}
+	return isHLSPath(path)
+}
+
+func isHLSPath(path string) bool {
+	return strings.HasSuffix(strings.ToLower(path), ".m3u8")
+}
@@ -0,0 +1,59 @@
+package player
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/util"
+)
+
+// AnimeDownloadDir returns the folder the episodes of the given anime are downloaded to.
+func AnimeDownloadDir(animeURL string) (string, error) {
+	root, err := util.DownloadsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, "anime", DownloadFolderFormatter(animeURL)), nil
+}
+
+// downloadedEpisodeRe matches the file names episodes are saved as, e.g. "12.mp4", or "12.1080p.mp4" with -quality-in-name.
+var downloadedEpisodeRe = regexp.MustCompile(`^(\d+)(?:\.[^.]+)?\.mp4$`)
+
+// LastDownloadedEpisode returns the highest episode number downloaded to dir.
+// The boolean is false when dir has no downloaded episodes or doesn't exist.
+func LastDownloadedEpisode(dir string) (int, bool) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, false
+	}
+
+	last, found := 0, false
+	for _, entry := range entries {
+		match := downloadedEpisodeRe.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		num, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		if !found || num > last {
+			last, found = num, true
+		}
+	}
+	return last, found
+}
+
+// EpisodesAfter returns the episodes numbered after last, keeping their order.
+func EpisodesAfter(episodes []api.Episode, last int) []api.Episode {
+	var newer []api.Episode
+	for _, ep := range episodes {
+		if ep.Num > last {
+			newer = append(newer, ep)
+		}
+	}
+	return newer
+}
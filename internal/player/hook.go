@@ -0,0 +1,84 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/util"
+)
+
+// completionHookTimeout bounds how long an -on-complete command may run.
+const completionHookTimeout = 2 * time.Minute
+
+// HookVars holds the values substituted into the -on-complete command.
+type HookVars struct {
+	File    string // Path of the downloaded episode, for {file}
+	Episode string // Episode number, for {episode}
+	Anime   string // Anime folder name, for {anime}
+}
+
+// HookArgs splits an -on-complete command into arguments and substitutes the placeholders in each one.
+// Splitting happens before substitution, so a file path with spaces stays a single argument and is never
+// interpreted by a shell.
+func HookArgs(command string, vars HookVars) []string {
+	replacer := strings.NewReplacer("{file}", vars.File, "{episode}", vars.Episode, "{anime}", vars.Anime)
+	fields := strings.Fields(command)
+	args := make([]string, 0, len(fields))
+	for _, field := range fields {
+		args = append(args, replacer.Replace(field))
+	}
+	return args
+}
+
+// RunCompletionHook runs command with the placeholders substituted, killing it after completionHookTimeout.
+func RunCompletionHook(command string, vars HookVars) error {
+	args := HookArgs(command, vars)
+	if len(args) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), completionHookTimeout)
+	defer cancel()
+
+	cmd := newCommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("on-complete command timed out after %s", completionHookTimeout)
+		}
+		return fmt.Errorf("on-complete command failed: %w", err)
+	}
+	return nil
+}
+
+// onEpisodeDownloaded checks a finished episode download with -verify, adds the -thumbnails cover art, records it in
+// the -dedupe index and runs the -on-complete command, when they are enabled. Failures are logged and never abort the
+// remaining downloads. It returns false when -verify rejected the file, which is then no longer at episodePath.
+func onEpisodeDownloaded(videoURL, episodePath, episodeNumberStr, animeURL string) bool {
+	if err := verifyDownload(episodePath); err != nil {
+		log.Printf("Episode %s: %v\n", episodeNumberStr, err)
+		return false
+	}
+	if util.CurrentConfig().Thumbnails {
+		if err := addThumbnail(episodePath, animeURL); err != nil {
+			log.Printf("Episode %s: %v\n", episodeNumberStr, err)
+		}
+	}
+	recordDownload(videoURL, episodePath)
+
+	command := util.CurrentConfig().OnComplete
+	if command == "" {
+		return true
+	}
+
+	vars := HookVars{File: episodePath, Episode: episodeNumberStr, Anime: DownloadFolderFormatter(animeURL)}
+	if err := RunCompletionHook(command, vars); err != nil {
+		log.Printf("Episode %s: %v\n", episodeNumberStr, err)
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ErrDownloadLocked is returned when another GoAnime process is downloading the same episode.
+var ErrDownloadLocked = errors.New("episode is already being downloaded")
+
+// AcquireDownloadLock marks episodePath as being downloaded by this process, so a second GoAnime downloading the
+// same episode doesn't write into the same part files. The lock is "<episodePath>.lock" holding the owner's PID;
+// a lock left behind by a process that is gone is reclaimed.
+//
+// Parameters:
+// - episodePath: the file the episode is downloaded to.
+//
+// Returns:
+// - func(): releases the lock; call it once the download has finished or failed.
+// - error: ErrDownloadLocked if a running process holds the lock, or an error if the lock can't be created.
+func AcquireDownloadLock(episodePath string) (func(), error) {
+	lockPath := episodePath + ".lock"
+
+	// The second attempt follows removing a stale lock
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_, err = fmt.Fprintf(file, "%d\n", os.Getpid())
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				_ = os.Remove(lockPath)
+				return nil, fmt.Errorf("failed to write download lock: %w", err)
+			}
+			return func() {
+				if err := os.Remove(lockPath); err != nil && !os.IsNotExist(err) {
+					log.Printf("Failed to remove download lock: %v\n", err)
+				}
+			}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create download lock: %w", err)
+		}
+
+		if pid, ok := lockOwner(lockPath); ok && processAlive(pid) {
+			return nil, fmt.Errorf("%w: %s is being downloaded by process %d", ErrDownloadLocked, filepath.Base(episodePath), pid)
+		}
+		log.Printf("Removing stale download lock %s\n", lockPath)
+		if err := os.Remove(lockPath); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove stale download lock: %w", err)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s was locked again while reclaiming a stale lock", ErrDownloadLocked, filepath.Base(episodePath))
+}
+
+// lockOwner reads the PID stored in a lock file.
+func lockOwner(lockPath string) (int, bool) {
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid, err == nil && pid > 0
+}
+
+// processAlive reports whether a process with the given PID is running.
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// On Windows FindProcess only succeeds for running processes; elsewhere it always does, and signal 0 checks
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,116 @@
+package player
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/util"
+)
+
+// EpisodeFileInfo holds the values an -output-template can refer to.
+type EpisodeFileInfo struct {
+	Anime   string
+	Season  int
+	Episode int
+	Title   string
+	Ext     string
+}
+
+// templateFieldRe matches "{field}" and "{field:02d}" placeholders.
+var templateFieldRe = regexp.MustCompile(`\{(\w+)(?::0?(\d+)d)?\}`)
+
+// unsafeFileCharsRe matches characters that are not allowed, or are awkward, in file names on common filesystems.
+var unsafeFileCharsRe = regexp.MustCompile(`[/\\:*?"<>|\x00-\x1f]`)
+
+// RenderOutputTemplate renders an -output-template into a relative file path.
+// Placeholders are {anime}, {season}, {episode}, {title} and {ext}; numbers accept a width such as {episode:02d}.
+// Missing values fall back to something sensible: AnimeFire lists each season as its own anime, so the season
+// defaults to 1, and an untitled episode is called "Episode N". Values are sanitized so they can't add
+// directories, while slashes written in the template itself do.
+func RenderOutputTemplate(template string, info EpisodeFileInfo) string {
+	if info.Season == 0 {
+		info.Season = 1
+	}
+	if info.Title == "" {
+		info.Title = fmt.Sprintf("Episode %d", info.Episode)
+	}
+	if info.Anime == "" {
+		info.Anime = "unknown"
+	}
+	if info.Ext == "" {
+		info.Ext = "mp4"
+	}
+
+	rendered := templateFieldRe.ReplaceAllStringFunc(template, func(placeholder string) string {
+		match := templateFieldRe.FindStringSubmatch(placeholder)
+		field, width := match[1], match[2]
+
+		var number int
+		switch field {
+		case "anime":
+			return sanitizeFileName(info.Anime)
+		case "title":
+			return sanitizeFileName(info.Title)
+		case "ext":
+			return sanitizeFileName(info.Ext)
+		case "season":
+			number = info.Season
+		case "episode":
+			number = info.Episode
+		default:
+			return placeholder
+		}
+		if width != "" {
+			return fmt.Sprintf("%0*d", mustAtoi(width), number)
+		}
+		return strconv.Itoa(number)
+	})
+
+	// Drop empty, "." and ".." segments so the result stays inside the downloads folder
+	var segments []string
+	for _, segment := range strings.Split(rendered, "/") {
+		segment = strings.TrimRight(strings.TrimSpace(segment), ".")
+		if segment == "" {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	return filepath.Join(segments...)
+}
+
+// sanitizeFileName replaces characters that can't appear in a file name.
+func sanitizeFileName(name string) string {
+	return strings.TrimSpace(unsafeFileCharsRe.ReplaceAllString(name, "_"))
+}
+
+// mustAtoi converts a string of digits matched by templateFieldRe.
+func mustAtoi(digits string) int {
+	n, _ := strconv.Atoi(digits)
+	return n
+}
+
+// EpisodeFilePath returns where an episode is saved. By default that is "<episode>.mp4" in the anime's download folder;
+// with -output-template the rendered template is used instead, relative to the folder holding every anime's download
+// folder (<downloads>/anime), so "{anime}/{episode}.{ext}" names episodes like the default does.
+func EpisodeFilePath(downloadPath, episodeNumberStr string, episode api.Episode) string {
+	template := util.CurrentConfig().OutputTemplate
+	if template == "" {
+		return filepath.Join(downloadPath, episodeNumberStr+".mp4")
+	}
+
+	num := episode.Num
+	if num == 0 {
+		num, _ = strconv.Atoi(ExtractEpisodeNumber(episodeNumberStr))
+	}
+	info := EpisodeFileInfo{
+		Anime:   filepath.Base(downloadPath),
+		Episode: num,
+		Title:   episode.Title.English,
+		Ext:     "mp4",
+	}
+	return filepath.Join(filepath.Dir(downloadPath), RenderOutputTemplate(template, info))
+}
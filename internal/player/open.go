@@ -0,0 +1,34 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrPlayerNotFound is returned when mpv is not installed.
+var ErrPlayerNotFound = errors.New("mpv not found")
+
+// OpenCommand returns the command that opens target with the system's default handler on goos,
+// such as the browser for a stream URL.
+func OpenCommand(goos, target string) (string, []string) {
+	switch goos {
+	case "windows":
+		// start is a cmd builtin; the empty argument is the window title, so a quoted target isn't taken for one
+		return "cmd", []string{"/c", "start", "", target}
+	case "darwin":
+		return "open", []string{target}
+	default:
+		return "xdg-open", []string{target}
+	}
+}
+
+// openWithSystemHandler hands the video to the system's default handler when mpv is missing,
+// so it can at least be watched in the browser. GoAnime can't follow playback there.
+func openWithSystemHandler(goos, videoURL string) error {
+	name, args := OpenCommand(goos, videoURL)
+	fmt.Printf("mpv is not installed, opening the episode with %s instead. Install mpv for skipping, Discord presence and episode navigation.\n", name)
+	if err := newCommand(name, args...).Start(); err != nil {
+		return fmt.Errorf("failed to open the video with %s: %w", name, err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package player
 
 import (
 	"bufio"
+	"context"
 	"crypto/rand"
 	"encoding/json"
 	"fmt"
@@ -12,13 +13,13 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
-	"os/user"
 	"path/filepath"
 	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
@@ -118,14 +119,14 @@ func (rpu *RichPresenceUpdater) Start() {
 			case <-ticker.C:
 				go rpu.updateDiscordPresence() // Run update asynchronously
 			case <-rpu.done:
-				if util.IsDebug {
+				if util.CurrentConfig().Debug {
 					log.Println("Rich Presence updater received stop signal.")
 				}
 				return
 			}
 		}
 	}()
-	if util.IsDebug {
+	if util.CurrentConfig().Debug {
 		log.Println("Rich Presence updater started.")
 	}
 }
@@ -134,7 +135,7 @@ func (rpu *RichPresenceUpdater) Start() {
 func (rpu *RichPresenceUpdater) Stop() {
 	close(rpu.done)
 	rpu.wg.Wait()
-	if util.IsDebug {
+	if util.CurrentConfig().Debug {
 		log.Println("Rich Presence updater stopped.")
 
 	}
@@ -146,14 +147,14 @@ func (rpu *RichPresenceUpdater) updateDiscordPresence() {
 
 	currentPosition, err := rpu.getCurrentPlaybackPosition()
 	if err != nil {
-		if util.IsDebug {
+		if util.CurrentConfig().Debug {
 			log.Printf("Error fetching playback position: %v\n", err)
 		}
 		return
 	}
 
 	// Debug log to check episode duration
-	if util.IsDebug {
+	if util.CurrentConfig().Debug {
 		log.Printf("Episode Duration in updateDiscordPresence: %v seconds (%v minutes)\n", rpu.episodeDuration.Seconds(), rpu.episodeDuration.Minutes())
 
 	}
@@ -182,7 +183,7 @@ func (rpu *RichPresenceUpdater) updateDiscordPresence() {
 
 	// Set the activity in Discord Rich Presence
 	if err := client.SetActivity(activity); err != nil {
-		if util.IsDebug {
+		if util.CurrentConfig().Debug {
 			log.Printf("Error updating Discord Rich Presence: %v\n", err)
 		} else {
 			log.Printf("Discord Rich Presence updated with elapsed time: %s\n", timeInfo)
@@ -206,8 +207,11 @@ func StartVideo(link string, args []string) (string, error) {
 		socketPath = fmt.Sprintf("/tmp/goanime_mpvsocket_%s", randomNumber)
 	}
 
-	mpvArgs := append([]string{"--no-terminal", "--quiet", fmt.Sprintf("--input-ipc-server=%s", socketPath), link}, args...)
-	cmd := exec.Command("mpv", mpvArgs...)
+	if _, err := exec.LookPath("mpv"); err != nil {
+		return "", ErrPlayerNotFound
+	}
+
+	cmd := newCommand("mpv", MPVArgs(link, socketPath, args)...)
 	err = cmd.Start()
 	if err != nil {
 		return "", fmt.Errorf("failed to start mpv: %w", err)
@@ -404,10 +408,11 @@ func tickCmd() tea.Cmd {
 //
 // This function extracts a specific part of the anime video URL to use it as the name
 // for the download folder. It uses a regular expression to capture the part of the URL
-// after "/video/", which is often unique and suitable as a folder name.
+// after "/video/" or "/animes/", which is often unique and suitable as a folder name.
 //
 // Steps:
-// 1. Compiles a regular expression that matches URLs of the form "https://<domain>/video/<unique-part>".
+// 1. Compiles a regular expression that matches URLs of the form "https://<domain>/video/<unique-part>"
+// or "https://<domain>/animes/<unique-part>".
 // 2. Extracts the "<unique-part>" from the URL.
 // 3. If the match is successful, it returns the extracted part as the folder name.
 // 4. If no match is found, it returns an empty string.
@@ -418,8 +423,8 @@ func tickCmd() tea.Cmd {
 // Returns:
 // - A string representing the formatted folder name, or an empty string if no match is found.
 func DownloadFolderFormatter(str string) string {
-	// Regular expression to capture the unique part after "/video/"
-	regex := regexp.MustCompile(`https?://[^/]+/video/([^/?]+)`)
+	// Regular expression to capture the unique part after "/video/" or "/animes/"
+	regex := regexp.MustCompile(`https?://[^/]+/(?:video|animes)/([^/?]+)`)
 
 	// Apply the regex to the input URL
 	match := regex.FindStringSubmatch(str)
@@ -435,17 +440,24 @@ func DownloadFolderFormatter(str string) string {
 }
 
 // getContentLength retrieves the content length of the given URL.
-func getContentLength(url string, client *http.Client) (int64, error) {
+// It returns 0 without an error when the server doesn't announce a length, as with chunked transfer encoding;
+// DownloadVideo then downloads the file as a single stream.
+func getContentLength(ctx context.Context, url string, client *http.Client) (int64, error) {
 	// Attempts to create an HTTP HEAD request to retrieve headers without downloading the body.
-	req, err := http.NewRequest("HEAD", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "HEAD", url, nil)
 	if err != nil {
 		// Returns 0 and the error if the request creation fails.
 		return 0, err
 	}
+	util.Debugf(ctx, "HEAD %s", req.URL.Host)
 
 	// Sends the HEAD request to the server.
 	resp, err := client.Do(req)
 	if err != nil || resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented {
+		if err == nil {
+			// Frees the connection, and its host slot, before asking again
+			_ = resp.Body.Close()
+		}
 		// If the HEAD request fails or is not supported, fall back to a GET request.
 		req.Method = "GET"
 		req.Header.Set("Range", "bytes=0-0") // Requests only the first byte to minimize data transfer.
@@ -474,8 +486,9 @@ func getContentLength(url string, client *http.Client) (int64, error) {
 	// Retrieves the "Content-Length" header from the response.
 	contentLengthHeader := resp.Header.Get("Content-Length")
 	if contentLengthHeader == "" {
-		// Returns an error if the "Content-Length" header is missing.
-		return 0, fmt.Errorf("Content-Length header is missing")
+		// A chunked response has no length, but the file can still be downloaded in one piece.
+		util.Debugf(ctx, "%s sent no Content-Length, downloading it as a single stream", req.URL.Host)
+		return 0, nil
 	}
 
 	// Converts the "Content-Length" header from a string to an int64.
@@ -495,6 +508,7 @@ func getContentLength(url string, client *http.Client) (int64, error) {
 // It saves the downloaded part as a temporary file and updates the progress state as data is received.
 //
 // Parameters:
+// - ctx: The context of the download, carrying its trace ID.
 // - url: The URL of the video file to download.
 // - from: The starting byte of the file part to download.
 // - to: The ending byte of the file part to download.
@@ -502,16 +516,18 @@ func getContentLength(url string, client *http.Client) (int64, error) {
 // - client: The HTTP client used to make the request.
 // - destPath: The destination path where the downloaded file part will be saved.
 // - m: The model containing the progress and state information.
+// - written: A counter incremented with the number of bytes this part has written.
 //
 // Returns:
 // - An error if the download fails, or nil if it succeeds.
-func downloadPart(url string, from, to int64, part int, client *http.Client, destPath string, m *model) error {
+func downloadPart(ctx context.Context, url string, from, to int64, part int, client *http.Client, destPath string, m *model, written *int64) error {
 	// Creates a new HTTP GET request for the specified URL.
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		// Returns the error if the request creation fails.
 		return err
 	}
+	util.Debugf(ctx, "GET %s bytes=%d-%d (thread %d)", req.URL.Host, from, to, part)
 
 	// Adds a "Range" header to specify the byte range to download (from 'from' to 'to').
 	req.Header.Add("Range", fmt.Sprintf("bytes=%d-%d", from, to))
@@ -550,11 +566,14 @@ func downloadPart(url string, from, to int64, part int, client *http.Client, des
 		}
 	}(file)
 
+	// Reads through the -max-rate limiter, which is a no-op when no cap is set.
+	body := throttle(resp.Body)
+
 	// Creates a buffer of 32 KB to read the response data in chunks.
 	buf := make([]byte, 32*1024) // 32KB buffer
 	for {
 		// Reads data from the response body into the buffer.
-		n, err := resp.Body.Read(buf)
+		n, err := body.Read(buf)
 		if n > 0 {
 			// If data is read, write it to the file.
 			if _, err := file.Write(buf[:n]); err != nil {
@@ -563,9 +582,12 @@ func downloadPart(url string, from, to int64, part int, client *http.Client, des
 			}
 
 			// Updates the received byte count in the model.
-			m.mu.Lock()
-			m.received += int64(n) // Updates the progress with the number of bytes received.
-			m.mu.Unlock()
+			if m != nil {
+				m.mu.Lock()
+				m.received += int64(n) // Updates the progress with the number of bytes received.
+				m.mu.Unlock()
+			}
+			atomic.AddInt64(written, int64(n))
 		}
 
 		// If EOF is reached (end of file), the download for this part is complete.
@@ -583,6 +605,68 @@ func downloadPart(url string, from, to int64, part int, client *http.Client, des
 	return nil
 }
 
+// downloadSingleStream downloads the whole file in one request, for servers that send no Content-Length.
+// Progress is still reported through m as bytes arrive, although the total isn't known.
+func downloadSingleStream(ctx context.Context, url string, client *http.Client, destPath string, m *model) error {
+	startTime := time.Now()
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	util.Debugf(ctx, "GET %s (single stream)", req.URL.Host)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			log.Printf("Failed to close response body: %v\n", err)
+		}
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download failed with status code %d", resp.StatusCode)
+	}
+
+	file, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	defer func(file *os.File) {
+		err := file.Close()
+		if err != nil {
+			log.Printf("Failed to close file: %v\n", err)
+		}
+	}(file)
+
+	body := throttle(resp.Body)
+	buf := make([]byte, 32*1024)
+	var written int64
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			if _, writeErr := file.Write(buf[:n]); writeErr != nil {
+				return writeErr
+			}
+			written += int64(n)
+			if m != nil {
+				m.mu.Lock()
+				m.received += int64(n)
+				m.mu.Unlock()
+			}
+		}
+		if err == io.EOF {
+			reportStats(destPath, DownloadStats{Bytes: written, Elapsed: time.Since(startTime)})
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
 // combineParts combines downloaded parts into a single file.
 //
 // This function merges multiple downloaded parts of a file into one complete file. Each part is saved
@@ -676,24 +760,30 @@ func DownloadVideo(url, destPath string, numThreads int, m *model) error {
 	// Cleans the destination path to ensure it is valid and well-formed.
 	destPath = filepath.Clean(destPath)
 
-	// Creates an HTTP client with a custom transport that includes a 10-second timeout.
+	// Every thread of this download logs under one trace ID
+	ctx := util.WithTrace(context.Background())
+	util.Debugf(ctx, "Downloading %s with %d threads", filepath.Base(destPath), numThreads)
+
+	// Creates an HTTP client with a custom transport using the configured timeouts.
+	// Every range request takes a slot of the host's -host-concurrency limit.
 	httpClient := &http.Client{
-		Transport: api.SafeTransport(10 * time.Second),
+		Transport:     api.HostLimitedTransport(api.ConfiguredTransport()),
+		CheckRedirect: api.RedirectPolicy(util.CurrentConfig().MaxRedirects),
 	}
 
 	chunkSize := int64(0)   // Variable to store the size of each download chunk.
 	var contentLength int64 // Variable to store the total content length of the file.
 
 	// Retrieves the content length of the file from the URL.
-	contentLength, err := getContentLength(url, httpClient)
+	contentLength, err := getContentLength(ctx, url, httpClient)
 	if err != nil {
 		// Returns an error if the content length cannot be determined.
 		return err
 	}
 
-	// Returns an error if the content length is zero, indicating an invalid or empty file.
+	// Without a length the file can't be split into ranges, so it is downloaded in one request.
 	if contentLength == 0 {
-		return fmt.Errorf("content length is zero")
+		return downloadSingleStream(ctx, url, httpClient, destPath, m)
 	}
 
 	// Calculates the size of each chunk based on the total content length and the number of threads.
@@ -701,6 +791,9 @@ func DownloadVideo(url, destPath string, numThreads int, m *model) error {
 
 	var downloadWg sync.WaitGroup // WaitGroup to synchronize the completion of all download threads.
 
+	partBytes := make([]int64, numThreads) // Bytes written by each thread, reported by -stats.
+	startTime := time.Now()
+
 	// Loops over the number of threads to create a concurrent download for each chunk.
 	for i := 0; i < numThreads; i++ {
 		from := int64(i) * chunkSize // Starting byte for the current chunk.
@@ -719,7 +812,7 @@ func DownloadVideo(url, destPath string, numThreads int, m *model) error {
 			defer downloadWg.Done() // Marks the thread as done when it finishes.
 
 			// Downloads the part of the file corresponding to the byte range (from, to).
-			err := downloadPart(url, from, to, part, httpClient, destPath, m)
+			err := downloadPart(ctx, url, from, to, part, httpClient, destPath, m, &partBytes[part])
 			if err != nil {
 				// Logs an error if the download of this part fails.
 				log.Printf("Thread %d: download part failed: %v\n", part, err)
@@ -730,6 +823,12 @@ func DownloadVideo(url, destPath string, numThreads int, m *model) error {
 	// Waits for all download threads to complete before proceeding.
 	downloadWg.Wait()
 
+	stats := DownloadStats{Elapsed: time.Since(startTime), PartBytes: partBytes}
+	for _, n := range partBytes {
+		stats.Bytes += n
+	}
+	reportStats(destPath, stats)
+
 	// Combines all the downloaded parts into a single file.
 	err = combineParts(destPath, numThreads)
 	if err != nil {
@@ -737,6 +836,13 @@ func DownloadVideo(url, destPath string, numThreads int, m *model) error {
 		return fmt.Errorf("failed to combine parts: %v", err)
 	}
 
+	// A part cut short by a failed thread leaves a broken episode; start over with yt-dlp, which is slower but more forgiving
+	if err := CheckCombinedFile(destPath, contentLength); err != nil {
+		return RecoverCorruptDownload(destPath, numThreads, err, func() error {
+			return downloadWithYtDlp(url, destPath)
+		})
+	}
+
 	// Returns nil to indicate that the download and combination were successful.
 	return nil
 }
@@ -767,7 +873,7 @@ func DownloadVideo(url, destPath string, numThreads int, m *model) error {
 func HandleDownloadAndPlay(
 	videoURL string,
 	episodes []api.Episode,
-	selectedEpisodeNum int,
+	selectedIndex int, // Index of the selected episode in episodes
 	animeURL string,
 	episodeNumberStr string,
 	animeMalID int,
@@ -780,7 +886,7 @@ func HandleDownloadAndPlay(
 		downloadAndPlayEpisode(
 			videoURL,
 			episodes,
-			selectedEpisodeNum,
+			selectedIndex,
 			animeURL,
 			episodeNumberStr,
 			animeMalID,
@@ -793,10 +899,11 @@ func HandleDownloadAndPlay(
 		}
 	default:
 		// Play online
-		if err := playVideo(
+		if err := PlayEpisode(
 			videoURL,
 			episodes,
-			selectedEpisodeNum,
+			selectedIndex,
+			animeURL,
 			animeMalID,
 			updater,
 		); err != nil {
@@ -825,7 +932,7 @@ func HandleDownloadAndPlay(
 //
 //		// Check if the video URL is from Blogger
 //		if strings.Contains(videoURL, "blogger.com") {
-//			// Use yt-dlp to download the video from Blogger
+//			// Use yt-dlp to download the video
 //			fmt.Printf("Downloading episode %s with yt-dlp...\n", episodeNumberStr)
 //			cmd := exec.Command("yt-dlp", "--no-progress", "-o", episodePath, videoURL)
 //			if err := cmd.Run(); err != nil {
@@ -891,38 +998,57 @@ func HandleDownloadAndPlay(
 func downloadAndPlayEpisode(
 	videoURL string,
 	episodes []api.Episode,
-	selectedEpisodeNum int,
+	selectedIndex int,
 	animeURL string,
 	episodeNumberStr string,
 	animeMalID int, // Added animeMalID parameter
 	updater *RichPresenceUpdater,
 ) {
-	currentUser, err := user.Current()
+	downloadPath, err := AnimeDownloadDir(animeURL)
 	if err != nil {
 		log.Panicln("Failed to get current user:", util.ErrorHandler(err))
 	}
+	episodePath := episodeQualityPath(EpisodeFilePath(downloadPath, episodeNumberStr, episodes[selectedIndex]), videoURL)
 
-	downloadPath := filepath.Join(currentUser.HomeDir, ".local", "goanime", "downloads", "anime", DownloadFolderFormatter(animeURL))
-	episodePath := filepath.Join(downloadPath, episodeNumberStr+".mp4")
-
-	if _, err := os.Stat(downloadPath); os.IsNotExist(err) {
-		if err := os.MkdirAll(downloadPath, os.ModePerm); err != nil {
+	if _, err := os.Stat(filepath.Dir(episodePath)); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(episodePath), os.ModePerm); err != nil {
 			log.Panicln("Failed to create download directory:", util.ErrorHandler(err))
 		}
 	}
 
+	linkKnownDownload(videoURL, episodePath)
 	if _, err := os.Stat(episodePath); os.IsNotExist(err) {
-		numThreads := 4 // Define the number of threads for downloading
+		numThreads := util.CurrentConfig().Threads // Define the number of threads for downloading
 
-		// Check if the video URL is from Blogger
-		if strings.Contains(videoURL, "blogger.com") {
-			// Use yt-dlp to download the video from Blogger
+		// Another GoAnime downloading the same episode would write into the same part files
+		release, err := AcquireDownloadLock(episodePath)
+		if err != nil {
+			log.Println("Failed to download episode:", util.ErrorHandler(err))
+			return
+		}
+		defer release()
+
+		// Check whether the video source needs yt-dlp
+		if PreferredDownloader(videoURL) == DownloaderYtDlp && util.CurrentConfig().StreamWhileDownload {
+			// Play from a local server while yt-dlp is still downloading
+			fmt.Printf("Downloading and playing episode %s with yt-dlp...\n", episodeNumberStr)
+			err := streamWhileDownloading(videoURL, episodePath, func(localURL string) error {
+				return playVideo(localURL, episodes, selectedIndex, animeURL, animeMalID, updater)
+			})
+			if err != nil {
+				log.Panicln("Failed to stream while downloading:", util.ErrorHandler(err))
+			}
+			fmt.Printf("Download of episode %s completed!\n", episodeNumberStr)
+			onEpisodeDownloaded(videoURL, episodePath, episodeNumberStr, animeURL)
+			return
+		} else if PreferredDownloader(videoURL) == DownloaderYtDlp {
+			// Use yt-dlp to download the video
 			fmt.Printf("Downloading episode %s with yt-dlp...\n", episodeNumberStr)
-			cmd := exec.Command("yt-dlp", "--no-progress", "-o", episodePath, videoURL)
-			if err := cmd.Run(); err != nil {
+			if err := downloadWithYtDlp(videoURL, episodePath); err != nil {
 				log.Panicln("Failed to download video using yt-dlp:", util.ErrorHandler(err))
 			}
 			fmt.Printf("Download of episode %s completed!\n", episodeNumberStr)
+			onEpisodeDownloaded(videoURL, episodePath, episodeNumberStr, animeURL)
 		} else {
 			// Initialize progress model
 			m := &model{
@@ -938,9 +1064,10 @@ func downloadAndPlayEpisode(
 
 			// Get content length
 			httpClient := &http.Client{
-				Transport: api.SafeTransport(10 * time.Second),
+				Transport:     api.ConfiguredTransport(),
+				CheckRedirect: api.RedirectPolicy(util.CurrentConfig().MaxRedirects),
 			}
-			contentLength, err := getContentLength(videoURL, httpClient)
+			contentLength, err := getContentLength(context.Background(), videoURL, httpClient)
 			if err != nil {
 				log.Panicln("Failed to get content length:", util.ErrorHandler(err))
 			}
@@ -967,13 +1094,14 @@ func downloadAndPlayEpisode(
 			if _, err := p.Run(); err != nil {
 				log.Fatalf("error running progress bar: %v", err)
 			}
+			onEpisodeDownloaded(videoURL, episodePath, episodeNumberStr, animeURL)
 		}
 	} else {
 		fmt.Println("Video already downloaded.")
 	}
 
 	if askForPlayOffline() {
-		if err := playVideo(episodePath, episodes, selectedEpisodeNum, animeMalID, updater); err != nil {
+		if err := playVideo(episodePath, episodes, selectedIndex, animeURL, animeMalID, updater); err != nil {
 			log.Panicln("Failed to play video:", util.ErrorHandler(err))
 		}
 	}
@@ -1059,10 +1187,66 @@ func HandleBatchDownload(episodes []api.Episode, animeURL string) error {
 		return fmt.Errorf("invalid end episode number: %v", err)
 	}
 
+	if err := ConfirmBatchSize(CountEpisodesInRange(episodes, startNum, endNum), askToConfirmBatch); err != nil {
+		return err
+	}
+
+	return DownloadEpisodeRange(episodes, animeURL, startNum, endNum)
+}
+
+// DownloadEpisodeRange downloads every episode numbered from startNum to endNum, skipping the ones already downloaded.
+// Its progress is saved so that -resume-batch can finish it if it is interrupted.
+func DownloadEpisodeRange(episodes []api.Episode, animeURL string, startNum, endNum int) error {
 	if startNum > endNum {
 		return fmt.Errorf("start episode number cannot be greater than end episode number")
 	}
 
+	var job *BatchJob
+	if path, err := BatchJobPath(); err != nil {
+		log.Printf("Failed to save the batch download progress: %v\n", err)
+	} else {
+		job = NewBatchJob(path, animeURL, startNum, endNum)
+		if err := job.Save(); err != nil {
+			log.Printf("Failed to save the batch download progress: %v\n", err)
+			job = nil
+		}
+	}
+	return downloadEpisodeRange(episodes, animeURL, startNum, endNum, job)
+}
+
+// ResumeBatchDownload downloads the episodes of an interrupted batch download that were not downloaded yet.
+func ResumeBatchDownload(episodes []api.Episode, job *BatchJob) error {
+	remaining := job.Remaining()
+	if len(remaining) == 0 {
+		fmt.Println("The last batch download already finished.")
+		return job.Finish()
+	}
+	fmt.Printf("Resuming the batch download of episodes %d to %d, %d episode(s) left: %s\n",
+		job.Start, job.End, len(remaining), FormatEpisodeRanges(remaining))
+	return downloadEpisodeRange(episodes, job.AnimeURL, job.Start, job.End, job)
+}
+
+// downloadEpisodeRange downloads the episodes from startNum to endNum that job, when set, has not completed yet,
+// recording each finished one in it.
+func downloadEpisodeRange(episodes []api.Episode, animeURL string, startNum, endNum int, job *BatchJob) error {
+	// Forget the job once every episode is downloaded
+	if job != nil {
+		defer func() {
+			if err := job.Finish(); err != nil {
+				log.Printf("Failed to remove the batch download progress: %v\n", err)
+			}
+		}()
+	}
+
+	// Say which requested episodes the source doesn't have instead of silently skipping them
+	if missing := MissingEpisodes(episodes, startNum, endNum); len(missing) > 0 {
+		fmt.Printf("Warning: episode(s) %s are not available and will be skipped.\n", FormatEpisodeRanges(missing))
+		// Resuming can't download them either
+		for _, num := range missing {
+			recordBatchProgress(job, num)
+		}
+	}
+
 	// Initialize variables for progress bar
 	var m *model
 	var p *tea.Program
@@ -1070,7 +1254,8 @@ func HandleBatchDownload(episodes []api.Episode, animeURL string) error {
 
 	// Prepare to calculate total content length
 	httpClient := &http.Client{
-		Transport: api.SafeTransport(10 * time.Second),
+		Transport:     api.ConfiguredTransport(),
+		CheckRedirect: api.RedirectPolicy(util.CurrentConfig().MaxRedirects),
 	}
 
 	m = &model{
@@ -1086,26 +1271,18 @@ func HandleBatchDownload(episodes []api.Episode, animeURL string) error {
 
 	// Calculate total content length
 	for episodeNum := startNum; episodeNum <= endNum; episodeNum++ {
-		// Find the episode in the 'episodes' slice
-		var episode api.Episode
-		found := false
-		for _, ep := range episodes {
-			// Extract numeric part from ep.Number
-			epNumStr := ExtractEpisodeNumber(ep.Number)
-			epNum, err := strconv.Atoi(epNumStr)
-			if err != nil {
-				continue
-			}
-			if epNum == episodeNum {
-				episode = ep
-				found = true
-				break
-			}
+		if job != nil && job.IsCompleted(episodeNum) {
+			continue
 		}
+		// Find the episode in the 'episodes' slice
+		index, found := EpisodeIndex(episodes, episodeNum)
 		if !found {
 			log.Printf("Episode %d not found\n", episodeNum)
+			// Resuming can't find it either, so the job must not wait for it
+			recordBatchProgress(job, episodeNum)
 			continue
 		}
+		episode := episodes[index]
 
 		// Get video URL
 		videoURL, err := GetVideoURLForEpisode(episode.URL)
@@ -1114,14 +1291,14 @@ func HandleBatchDownload(episodes []api.Episode, animeURL string) error {
 			continue
 		}
 
-		// Check if the video URL is from Blogger
-		if strings.Contains(videoURL, "blogger.com") {
+		// Check whether the video source needs yt-dlp
+		if PreferredDownloader(videoURL) == DownloaderYtDlp {
 			// Skip adding content length for episodes using yt-dlp
 			continue
 		}
 
 		// Get content length
-		contentLength, err := getContentLength(videoURL, httpClient)
+		contentLength, err := getContentLength(context.Background(), videoURL, httpClient)
 		if err != nil {
 			log.Printf("Failed to get content length for episode %d: %v\n", episodeNum, err)
 			continue
@@ -1141,26 +1318,17 @@ func HandleBatchDownload(episodes []api.Episode, animeURL string) error {
 
 			// Now start downloads
 			for episodeNum := startNum; episodeNum <= endNum; episodeNum++ {
-				// Find the episode in the 'episodes' slice
-				var episode api.Episode
-				found := false
-				for _, ep := range episodes {
-					// Extract numeric part from ep.Number
-					epNumStr := ExtractEpisodeNumber(ep.Number)
-					epNum, err := strconv.Atoi(epNumStr)
-					if err != nil {
-						continue
-					}
-					if epNum == episodeNum {
-						episode = ep
-						found = true
-						break
-					}
+				if job != nil && job.IsCompleted(episodeNum) {
+					continue
 				}
+				// Find the episode in the 'episodes' slice
+				index, found := EpisodeIndex(episodes, episodeNum)
 				if !found {
 					log.Printf("Episode %d not found\n", episodeNum)
+					recordBatchProgress(job, episodeNum)
 					continue
 				}
+				episode := episodes[index]
 
 				// Get video URL
 				videoURL, err := GetVideoURLForEpisode(episode.URL)
@@ -1170,37 +1338,50 @@ func HandleBatchDownload(episodes []api.Episode, animeURL string) error {
 				}
 
 				// Build download path
-				currentUser, err := user.Current()
+				downloadPath, err := AnimeDownloadDir(animeURL)
 				if err != nil {
 					log.Panicln("Failed to get current user:", util.ErrorHandler(err))
 				}
-
-				downloadPath := filepath.Join(currentUser.HomeDir, ".local", "goanime", "downloads", "anime", DownloadFolderFormatter(animeURL))
 				episodeNumberStr := strconv.Itoa(episodeNum)
-				episodePath := filepath.Join(downloadPath, episodeNumberStr+".mp4")
+				episodePath := episodeQualityPath(EpisodeFilePath(downloadPath, episodeNumberStr, episode), videoURL)
 
-				if _, err := os.Stat(downloadPath); os.IsNotExist(err) {
-					if err := os.MkdirAll(downloadPath, os.ModePerm); err != nil {
+				if _, err := os.Stat(filepath.Dir(episodePath)); os.IsNotExist(err) {
+					if err := os.MkdirAll(filepath.Dir(episodePath), os.ModePerm); err != nil {
 						log.Panicln("Failed to create download directory:", util.ErrorHandler(err))
 					}
 				}
 
+				linkKnownDownload(videoURL, episodePath)
 				if _, err := os.Stat(episodePath); os.IsNotExist(err) {
-					numThreads := 4 // Define the number of threads for downloading
+					numThreads := util.CurrentConfig().Threads // Define the number of threads for downloading
 
 					overallWg.Add(1)
 					go func(videoURL, episodePath, episodeNumberStr string) {
 						defer overallWg.Done()
 
-						// Check if the video URL is from Blogger
-						if strings.Contains(videoURL, "blogger.com") {
-							// Use yt-dlp to download the video from Blogger
+						release, err := AcquireDownloadLock(episodePath)
+						if err != nil {
+							log.Printf("Skipping episode %s: %v\n", episodeNumberStr, err)
+							return
+						}
+						defer release()
+
+						// Check whether the video source needs yt-dlp
+						if PreferredDownloader(videoURL) == DownloaderYtDlp {
+							// Use yt-dlp to download the video
 							fmt.Printf("Downloading episode %s with yt-dlp...\n", episodeNumberStr)
-							cmd := exec.Command("yt-dlp", "--no-progress", "-o", episodePath, videoURL)
-							if err := cmd.Run(); err != nil {
+							// yt-dlp opens its own connections, so the whole run takes one slot of the host
+							releaseHost := api.AcquireHost(videoURL)
+							err := downloadWithYtDlp(videoURL, episodePath)
+							releaseHost()
+							if err != nil {
 								log.Printf("Failed to download video using yt-dlp: %v\n", err)
 							} else {
 								fmt.Printf("Download of episode %s completed!\n", episodeNumberStr)
+								// A file rejected by -verify is gone, so resuming must download it again
+								if onEpisodeDownloaded(videoURL, episodePath, episodeNumberStr, animeURL) {
+									recordBatchProgress(job, mustAtoi(episodeNumberStr))
+								}
 							}
 						} else {
 							// Update status
@@ -1208,11 +1389,16 @@ func HandleBatchDownload(episodes []api.Episode, animeURL string) error {
 
 							if err := DownloadVideo(videoURL, episodePath, numThreads, m); err != nil {
 								log.Printf("Failed to download episode %s: %v\n", episodeNumberStr, err)
+							} else {
+								if onEpisodeDownloaded(videoURL, episodePath, episodeNumberStr, animeURL) {
+									recordBatchProgress(job, mustAtoi(episodeNumberStr))
+								}
 							}
 						}
 					}(videoURL, episodePath, episodeNumberStr)
 				} else {
 					log.Printf("Episode %d already downloaded.\n", episodeNum)
+					recordBatchProgress(job, episodeNum)
 				}
 			}
 
@@ -1246,26 +1432,17 @@ func HandleBatchDownload(episodes []api.Episode, animeURL string) error {
 		var overallWg sync.WaitGroup
 
 		for episodeNum := startNum; episodeNum <= endNum; episodeNum++ {
-			// Find the episode in the 'episodes' slice
-			var episode api.Episode
-			found := false
-			for _, ep := range episodes {
-				// Extract numeric part from ep.Number
-				epNumStr := ExtractEpisodeNumber(ep.Number)
-				epNum, err := strconv.Atoi(epNumStr)
-				if err != nil {
-					continue
-				}
-				if epNum == episodeNum {
-					episode = ep
-					found = true
-					break
-				}
+			if job != nil && job.IsCompleted(episodeNum) {
+				continue
 			}
+			// Find the episode in the 'episodes' slice
+			index, found := EpisodeIndex(episodes, episodeNum)
 			if !found {
 				log.Printf("Episode %d not found\n", episodeNum)
+				recordBatchProgress(job, episodeNum)
 				continue
 			}
+			episode := episodes[index]
 
 			// Get video URL
 			videoURL, err := GetVideoURLForEpisode(episode.URL)
@@ -1275,49 +1452,66 @@ func HandleBatchDownload(episodes []api.Episode, animeURL string) error {
 			}
 
 			// Build download path
-			currentUser, err := user.Current()
+			downloadPath, err := AnimeDownloadDir(animeURL)
 			if err != nil {
 				log.Panicln("Failed to get current user:", util.ErrorHandler(err))
 			}
-
-			downloadPath := filepath.Join(currentUser.HomeDir, ".local", "goanime", "downloads", "anime", DownloadFolderFormatter(animeURL))
 			episodeNumberStr := strconv.Itoa(episodeNum)
-			episodePath := filepath.Join(downloadPath, episodeNumberStr+".mp4")
+			episodePath := episodeQualityPath(EpisodeFilePath(downloadPath, episodeNumberStr, episode), videoURL)
 
-			if _, err := os.Stat(downloadPath); os.IsNotExist(err) {
-				if err := os.MkdirAll(downloadPath, os.ModePerm); err != nil {
+			if _, err := os.Stat(filepath.Dir(episodePath)); os.IsNotExist(err) {
+				if err := os.MkdirAll(filepath.Dir(episodePath), os.ModePerm); err != nil {
 					log.Panicln("Failed to create download directory:", util.ErrorHandler(err))
 				}
 			}
 
+			linkKnownDownload(videoURL, episodePath)
 			if _, err := os.Stat(episodePath); os.IsNotExist(err) {
-				numThreads := 4 // Define the number of threads for downloading
+				numThreads := util.CurrentConfig().Threads // Define the number of threads for downloading
 
 				overallWg.Add(1)
 				go func(videoURL, episodePath, episodeNumberStr string) {
 					defer overallWg.Done()
 
-					// Check if the video URL is from Blogger
-					if strings.Contains(videoURL, "blogger.com") {
-						// Use yt-dlp to download the video from Blogger
+					release, err := AcquireDownloadLock(episodePath)
+					if err != nil {
+						log.Printf("Skipping episode %s: %v\n", episodeNumberStr, err)
+						return
+					}
+					defer release()
+
+					// Check whether the video source needs yt-dlp
+					if PreferredDownloader(videoURL) == DownloaderYtDlp {
+						// Use yt-dlp to download the video
 						fmt.Printf("Downloading episode %s with yt-dlp...\n", episodeNumberStr)
-						cmd := exec.Command("yt-dlp", "--no-progress", "-o", episodePath, videoURL)
-						if err := cmd.Run(); err != nil {
+						// yt-dlp opens its own connections, so the whole run takes one slot of the host
+						releaseHost := api.AcquireHost(videoURL)
+						err := downloadWithYtDlp(videoURL, episodePath)
+						releaseHost()
+						if err != nil {
 							log.Printf("Failed to download video using yt-dlp: %v\n", err)
 						} else {
 							fmt.Printf("Download of episode %s completed!\n", episodeNumberStr)
+							if onEpisodeDownloaded(videoURL, episodePath, episodeNumberStr, animeURL) {
+								recordBatchProgress(job, mustAtoi(episodeNumberStr))
+							}
 						}
 					} else {
 						// Use standard download method without progress bar
 						fmt.Printf("Downloading episode %s...\n", episodeNumberStr)
 						if err := DownloadVideo(videoURL, episodePath, numThreads, nil); err != nil {
 							log.Printf("Failed to download episode %s: %v\n", episodeNumberStr, err)
+						} else {
+							fmt.Printf("Download of episode %s completed!\n", episodeNumberStr)
+							if onEpisodeDownloaded(videoURL, episodePath, episodeNumberStr, animeURL) {
+								recordBatchProgress(job, mustAtoi(episodeNumberStr))
+							}
 						}
-						fmt.Printf("Download of episode %s completed!\n", episodeNumberStr)
 					}
 				}(videoURL, episodePath, episodeNumberStr)
 			} else {
 				log.Printf("Episode %d already downloaded.\n", episodeNum)
+				recordBatchProgress(job, episodeNum)
 			}
 		}
 
@@ -1328,28 +1522,37 @@ func HandleBatchDownload(episodes []api.Episode, animeURL string) error {
 	return nil
 }
 
-// SelectEpisodeWithFuzzyFinder allows the user to select an episode using fuzzy finder
-func SelectEpisodeWithFuzzyFinder(episodes []api.Episode) (string, string, error) {
+// SelectEpisodeWithFuzzyFinder allows the user to select an episode using fuzzy finder.
+// It returns the selected element of episodes; use its Num, not its label, to tell which episode it is.
+func SelectEpisodeWithFuzzyFinder(episodes []api.Episode) (int, error) {
 	if len(episodes) == 0 {
-		return "", "", errors.New("no episodes provided")
+		return -1, api.ErrNoEpisodes
 	}
 
-	idx, err := fuzzyfinder.Find(
-		episodes,
-		func(i int) string {
-			return episodes[i].Number
-		},
-		fuzzyfinder.WithPromptString("Select the episode"),
-	)
+	ctx, cancel := util.SelectionContext()
+	defer cancel()
+	idx, err := util.FindWithContext(ctx, func(ctx context.Context) (int, error) {
+		return fuzzyfinder.Find(
+			episodes,
+			func(i int) string {
+				if episodes[i].Title.English != "" {
+					return fmt.Sprintf("%s - %s", episodes[i].Number, episodes[i].Title.English)
+				}
+				return episodes[i].Number
+			},
+			fuzzyfinder.WithPromptString("Select the episode"),
+			fuzzyfinder.WithContext(ctx),
+		)
+	})
 	if err != nil {
-		return "", "", fmt.Errorf("failed to select episode with go-fuzzyfinder: %w", err)
+		return -1, fmt.Errorf("failed to select episode with go-fuzzyfinder: %w", err)
 	}
 
 	if idx < 0 || idx >= len(episodes) {
-		return "", "", errors.New("invalid index returned by fuzzyfinder")
+		return -1, errors.New("invalid index returned by fuzzyfinder")
 	}
 
-	return episodes[idx].URL, episodes[idx].Number, nil
+	return idx, nil
 }
 
 // ExtractEpisodeNumber extracts the numeric part of an episode string
@@ -1362,26 +1565,101 @@ func ExtractEpisodeNumber(episodeStr string) string {
 	return numStr
 }
 
-// GetVideoURLForEpisode gets the video URL for a given episode URL
+// GetVideoURLForEpisode gets the video URL for a given episode URL.
+// A URL resolved earlier in the session is reused while it is fresh and still reachable.
 func GetVideoURLForEpisode(episodeURL string) (string, error) {
+	return sessionStreams.VideoURL(episodeURL)
+}
 
-	if util.IsDebug {
-		log.Printf("Tentando extrair URL de vídeo para o episódio: %s", episodeURL)
-	}
-	videoURL, err := extractVideoURL(episodeURL)
-	if err != nil {
-		return "", err
+// resolveVideoURLForEpisode walks the scraping chain to the video URL of an episode, prompting for the quality when -ask-quality is set.
+func resolveVideoURLForEpisode(episodeURL string) (string, error) {
+	return resolveEpisode(episodeURL, selectSessionQuality)
+}
+
+// ResolveVideoURL resolves an episode like GetVideoURLForEpisode, without ever prompting for the quality.
+// It is used for background prefetching and by the serve mode.
+func ResolveVideoURL(episodeURL string) (string, error) {
+	return resolveEpisode(episodeURL, selectPrefetchQuality)
+}
+
+// ErrResolveTimeout is returned when resolving an episode takes longer than -resolve-timeout.
+var ErrResolveTimeout = errors.New("resolving the episode timed out")
+
+// resolveEpisode walks the scraping chain to the video URL of an episode within -resolve-timeout.
+// The timeout covers every request of the chain, but none of the download that follows.
+func resolveEpisode(episodeURL string, selectQuality func([]VideoData) (string, error)) (string, error) {
+	ctx := util.WithTrace(context.Background())
+	timeout := util.CurrentConfig().ResolveTimeout
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	util.Debugf(ctx, "Tentando extrair URL de vídeo para o episódio: %s", episodeURL)
+
+	actualVideoURL, attempts := resolveStream(ctx, episodeURL, selectQuality)
+	if len(attempts) > 0 {
+		// The scrapers flatten their errors to text, so tell a timeout apart by the context
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", errors.Wrapf(ErrResolveTimeout, "gave up after %s (-resolve-timeout)", timeout)
+		}
+		return "", &NoPlayableStreamError{Attempts: attempts}
 	}
-	return extractActualVideoURL(videoURL)
+	return actualVideoURL, nil
 }
 
-func extractVideoURL(url string) (string, error) {
+// resolveStream tries each server of the episode in turn and returns the first valid video URL.
+// When AnimeFire's player fails, the Blogger embed on the episode page, if there is one, is tried next.
+// The attempts are only returned when every server failed.
+func resolveStream(ctx context.Context, episodeURL string, selectQuality func([]VideoData) (string, error)) (string, []StreamAttempt) {
+	var attempts []StreamAttempt
+	try := func(videoSrc string) (string, bool) {
+		server := ServerPlayer
+		if strings.Contains(videoSrc, "blogger.com") {
+			server = ServerBlogger
+		}
+		videoURL, err := extractActualVideoURL(ctx, videoSrc, selectQuality)
+		if err == nil {
+			err = ValidateVideoURL(videoURL)
+		}
+		if err != nil {
+			attempts = append(attempts, StreamAttempt{Source: api.SourceName, Server: server, Quality: VideoQuality(videoURL), Err: err})
+			return "", false
+		}
+		return videoURL, true
+	}
 
-	if util.IsDebug {
-		log.Printf("Extraindo URL de vídeo da página: %s", url)
+	videoSrc, err := extractVideoURL(ctx, episodeURL)
+	if err != nil {
+		return "", []StreamAttempt{{Source: api.SourceName, Server: ServerEpisodePage, Err: err}}
+	}
+	if videoURL, ok := try(videoSrc); ok {
+		return videoURL, nil
 	}
+	if ctx.Err() != nil || strings.Contains(videoSrc, "blogger.com") {
+		return "", attempts
+	}
+
+	content, err := fetchContent(ctx, episodeURL)
+	if err != nil {
+		util.Debugf(ctx, "Failed to fetch the episode page again for a Blogger embed: %v", err)
+		return "", attempts
+	}
+	bloggerURL, err := findBloggerLink(content)
+	if err != nil {
+		return "", attempts
+	}
+	util.Debugf(ctx, "AnimeFire's player failed, trying the Blogger embed: %s", bloggerURL)
+	if videoURL, ok := try(bloggerURL); ok {
+		return videoURL, nil
+	}
+	return "", attempts
+}
+
+func extractVideoURL(ctx context.Context, url string) (string, error) {
+	util.Debugf(ctx, "Extraindo URL de vídeo da página: %s", url)
 
-	response, err := api.SafeGet(url)
+	response, err := api.SafeGetContext(ctx, url)
 	if err != nil {
 		return "", errors.New(fmt.Sprintf("failed to fetch URL: %+v", err))
 	}
@@ -1408,7 +1686,7 @@ func extractVideoURL(url string) (string, error) {
 
 	videoSrc, exists := videoElements.Attr("data-video-src")
 	if !exists || videoSrc == "" {
-		urlBody, err := fetchContent(url)
+		urlBody, err := fetchContent(ctx, url)
 		if err != nil {
 			return "", err
 		}
@@ -1421,8 +1699,8 @@ func extractVideoURL(url string) (string, error) {
 	return videoSrc, nil
 }
 
-func fetchContent(url string) (string, error) {
-	resp, err := api.SafeGet(url)
+func fetchContent(ctx context.Context, url string) (string, error) {
+	resp, err := api.SafeGetContext(ctx, url)
 	if err != nil {
 		return "", err
 	}
@@ -1454,13 +1732,57 @@ func findBloggerLink(content string) (string, error) {
 	}
 }
 
-func extractActualVideoURL(videoSrc string) (string, error) {
+// emptyVideoDataRetries is how many more times the video JSON is fetched when it lists no videos;
+// AnimeFire often fills the list in on a later request.
+const emptyVideoDataRetries = 2
+
+// emptyVideoDataDelay is the pause before fetching an empty video JSON again.
+const emptyVideoDataDelay = 300 * time.Millisecond
+
+func extractActualVideoURL(ctx context.Context, videoSrc string, selectQuality func([]VideoData) (string, error)) (string, error) {
 	if strings.Contains(videoSrc, "blogger.com") {
 		return videoSrc, nil
 	}
-	response, err := api.SafeGet(videoSrc)
+
+	var videos []VideoData
+	for attempt := 0; ; attempt++ {
+		var err error
+		videos, err = fetchVideoData(ctx, videoSrc)
+		if err != nil {
+			return "", err
+		}
+		if len(videos) > 0 || attempt == emptyVideoDataRetries {
+			break
+		}
+		util.Debugf(ctx, "No video data in the response, trying again (%d/%d)", attempt+1, emptyVideoDataRetries)
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(emptyVideoDataDelay):
+		}
+	}
+
+	if len(videos) == 0 {
+		return "", errors.New("no video data found in the response")
+	}
+
+	selectedVideoURL, err := selectQuality(videos)
 	if err != nil {
-		return "", errors.New(fmt.Sprintf("failed to fetch video source: %+v", err))
+		return "", err
+	}
+	if selectedVideoURL == "" {
+		return "", errors.New("no suitable video quality found")
+	}
+	rememberVideoQuality(videos, selectedVideoURL)
+
+	return selectedVideoURL, nil
+}
+
+// fetchVideoData fetches the video JSON of an episode and returns the qualities it lists.
+func fetchVideoData(ctx context.Context, videoSrc string) ([]VideoData, error) {
+	response, err := api.SafeGetContext(ctx, videoSrc)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("failed to fetch video source: %+v", err))
 	}
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
@@ -1470,35 +1792,49 @@ func extractActualVideoURL(videoSrc string) (string, error) {
 	}(response.Body)
 
 	if response.StatusCode != http.StatusOK {
-		return "", errors.New(fmt.Sprintf("request failed with status: %s", response.Status))
+		return nil, errors.New(fmt.Sprintf("request failed with status: %s", response.Status))
 	}
 
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
-		return "", errors.New(fmt.Sprintf("failed to read response body: %+v", err))
+		return nil, errors.New(fmt.Sprintf("failed to read response body: %+v", err))
 	}
 
 	var videoResponse VideoResponse
 	if err := json.Unmarshal(body, &videoResponse); err != nil {
-		return "", errors.New(fmt.Sprintf("failed to unmarshal JSON response: %+v", err))
-	}
-
-	if len(videoResponse.Data) == 0 {
-		return "", errors.New("no video data found in the response")
+		return nil, errors.New(fmt.Sprintf("failed to unmarshal JSON response: %+v", err))
 	}
-
-	highestQualityVideoURL := selectHighestQualityVideo(videoResponse.Data)
-	if highestQualityVideoURL == "" {
-		return "", errors.New("no suitable video quality found")
-	}
-
-	return highestQualityVideoURL, nil
+	return videoResponse.Data, nil
 }
 
 // VideoData represents the video data structure, with a source URL and a label
 type VideoData struct {
 	Src   string `json:"src"`
 	Label string `json:"label"`
+	Type  string `json:"type,omitempty"` // MIME type, when the site gives one
+}
+
+// Containers a video can come in, as returned by VideoData.Container.
+const (
+	ContainerFile = "file" // A progressive video file such as an MP4
+	ContainerHLS  = "hls"  // An HLS playlist, downloaded with yt-dlp
+)
+
+// Container tells an HLS playlist from a progressive video file, by its MIME type or else its URL.
+func (v VideoData) Container() string {
+	if strings.Contains(strings.ToLower(v.Type), "mpegurl") || isHLSPlaylist(v.Src) {
+		return ContainerHLS
+	}
+	return ContainerFile
+}
+
+// DisplayLabel is the label shown when choosing a quality; HLS entries are marked so that an episode offered
+// both as a file and as HLS in the same quality lists two distinct choices.
+func (v VideoData) DisplayLabel() string {
+	if v.Container() == ContainerHLS {
+		return v.Label + " (HLS)"
+	}
+	return v.Label
 }
 
 // VideoResponse represents the video response structure with a slice of VideoData
@@ -1506,38 +1842,67 @@ type VideoResponse struct {
 	Data []VideoData `json:"data"`
 }
 
-// selectHighestQualityVideo selects the highest quality video available
+// selectHighestQualityVideo selects the highest quality video available, preferring a file over HLS in the same quality.
+// With -prefer-mp4, progressive videos win over HLS playlists of any quality.
 func selectHighestQualityVideo(videos []VideoData) string {
+	if util.CurrentConfig().PreferMP4 {
+		var progressive []VideoData
+		for _, video := range videos {
+			if video.Container() != ContainerHLS {
+				progressive = append(progressive, video)
+			}
+		}
+		if len(progressive) > 0 {
+			videos = progressive
+		}
+	}
+
 	var highestQuality int
-	var highestQualityURL string
-	for _, video := range videos {
+	var highest *VideoData
+	for i, video := range videos {
 		qualityValue, _ := strconv.Atoi(strings.TrimRight(video.Label, "p"))
-		if qualityValue > highestQuality {
+		if qualityValue > highestQuality ||
+			(highest != nil && qualityValue == highestQuality && highest.Container() == ContainerHLS && video.Container() != ContainerHLS) {
 			highestQuality = qualityValue
-			highestQualityURL = video.Src
+			highest = &videos[i]
 		}
 	}
-	return highestQualityURL
+	if highest == nil {
+		return ""
+	}
+	return highest.Src
+}
+
+// PlayEpisode plays episodes[index] from videoURL without downloading it, letting the user move to the
+// next and previous episodes. The episode is given by its index, never looked up by number or label, so
+// movies and episodes labelled without a number play as themselves.
+func PlayEpisode(videoURL string, episodes []api.Episode, index int, animeURL string, animeMalID int, updater *RichPresenceUpdater) error {
+	return playVideo(videoURL, episodes, index, animeURL, animeMalID, updater)
 }
 
 // playVideo handles the online playback of a video and user interaction.
 func playVideo(
 	videoURL string,
 	episodes []api.Episode,
-	currentEpisodeNum int,
+	currentEpisodeIndex int,
+	animeURL string,
 	animeMalID int, // Added animeMalID parameter
 	updater *RichPresenceUpdater,
 ) error {
 	// Fetch AniSkip data for the current episode
-	if util.IsDebug {
+	if util.CurrentConfig().Debug {
 		log.Printf("Video URL: %s", videoURL)
 	}
 
-	currentEpisode := &episodes[currentEpisodeNum-1]
+	if currentEpisodeIndex < 0 || currentEpisodeIndex >= len(episodes) {
+		return fmt.Errorf("episode index %d out of range for %d episodes", currentEpisodeIndex, len(episodes))
+	}
+	currentEpisode := &episodes[currentEpisodeIndex]
+	currentEpisodeNum := currentEpisode.Num
 	err := api.GetAndParseAniSkipData(animeMalID, currentEpisodeNum, currentEpisode)
 	if err != nil {
 		log.Printf("AniSkip data not available for episode %d: %v\n", currentEpisodeNum, err)
-	} else if util.IsDebug {
+	} else if util.CurrentConfig().Debug {
 		log.Printf("AniSkip data for episode %d: %+v\n", currentEpisodeNum, currentEpisode.SkipTimes)
 	}
 
@@ -1552,12 +1917,23 @@ func playVideo(
 		mpvArgs = append(mpvArgs, fmt.Sprintf("--script-opts=skip_ed=%d-%d", edStart, edEnd))
 	}
 
+	// Start where the episode was stopped last time
+	resumePositions := loadSessionResume()
+	mpvArgs = append(mpvArgs, resumeStartArgs(resumePositions, animeURL, currentEpisodeNum)...)
+
 	// Start mpv with IPC support
 	socketPath, err := StartVideo(videoURL, mpvArgs)
+	if errors.Is(err, ErrPlayerNotFound) && !util.CurrentConfig().NoFallbackOpen {
+		return openWithSystemHandler(runtime.GOOS, videoURL)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to start video with IPC: %w", err)
 	}
 
+	// Save the playback position while the episode plays
+	stopTracking := trackResumePosition(resumePositions, socketPath, animeURL, currentEpisodeNum)
+	defer stopTracking()
+
 	// Only proceed with Rich Presence updates if updater is not nil
 	if updater != nil {
 		// Wait for the episode to start before retrieving the duration
@@ -1566,7 +1942,7 @@ func playVideo(
 				// Get current playback time
 				timePos, err := mpvSendCommand(socketPath, []interface{}{"get_property", "time-pos"})
 				if err != nil {
-					if util.IsDebug {
+					if util.CurrentConfig().Debug {
 						log.Printf("Error getting playback time: %v", err)
 					}
 				}
@@ -1594,7 +1970,7 @@ func playVideo(
 						if duration, ok := durationPos.(float64); ok {
 							// Set episodeDuration correctly in seconds
 							updater.episodeDuration = time.Duration(duration * float64(time.Second))
-							if util.IsDebug {
+							if util.CurrentConfig().Debug {
 								log.Printf("Retrieved Video duration: %v seconds", updater.episodeDuration.Seconds())
 							}
 
@@ -1619,16 +1995,9 @@ func playVideo(
 		defer updater.Stop()
 	}
 
-	// Locate the index of the current episode
-	currentEpisodeIndex := -1
-	for i, ep := range episodes {
-		if ExtractEpisodeNumber(ep.Number) == strconv.Itoa(currentEpisodeNum) {
-			currentEpisodeIndex = i
-			break
-		}
-	}
-	if currentEpisodeIndex == -1 {
-		return fmt.Errorf("current episode number %d not found", currentEpisodeNum)
+	// Resolve the upcoming episodes while this one plays
+	if util.CurrentConfig().PrefetchCount > 0 {
+		episodePrefetcher.Schedule(episodes, currentEpisodeIndex, util.CurrentConfig().PrefetchCount)
 	}
 
 	// Command loop for user interaction
@@ -1643,66 +2012,42 @@ func playVideo(
 		}
 
 		switch char {
-		case 'n': // Next episode
-			if currentEpisodeIndex+1 < len(episodes) {
-				nextEpisode := episodes[currentEpisodeIndex+1]
-				if updater != nil {
-					updater.Stop()
-				}
-				nextVideoURL, err := GetVideoURLForEpisode(nextEpisode.URL)
-				if err != nil {
-					fmt.Printf("Failed to get video URL for next episode: %v\n", err)
-					continue
-				}
-				// Set duration for the next episode
-				nextEpisodeDuration := time.Duration(nextEpisode.Duration) * time.Second
-				var newUpdater *RichPresenceUpdater
-				if updater != nil {
-					newUpdater = NewRichPresenceUpdater(
-						updater.anime,
-						updater.isPaused,
-						updater.animeMutex,
-						updater.updateFreq,
-						nextEpisodeDuration,
-						"",
-					)
-					updater.episodeStarted = false
-				}
-				return playVideo(nextVideoURL, episodes, currentEpisodeNum+1, animeMalID, newUpdater)
-			} else {
-				fmt.Println("Already at the last episode.")
+		case 'n', 'p': // Next or previous episode
+			step, edge := 1, "last"
+			if char == 'p' {
+				step, edge = -1, "first"
 			}
-		case 'p': // Previous episode
-			if currentEpisodeIndex > 0 {
-				prevEpisode := episodes[currentEpisodeIndex-1]
-				if updater != nil {
-					updater.Stop()
-				}
-				prevVideoURL, err := GetVideoURLForEpisode(prevEpisode.URL)
-				if err != nil {
-					fmt.Printf("Failed to get video URL for previous episode: %v\n", err)
-					continue
-				}
-				// Set duration for the previous episode
-				prevEpisodeDuration := time.Duration(prevEpisode.Duration) * time.Second
-				var newUpdater *RichPresenceUpdater
-				if updater != nil {
-					newUpdater = NewRichPresenceUpdater(
-						updater.anime,
-						updater.isPaused,
-						updater.animeMutex,
-						updater.updateFreq,
-						prevEpisodeDuration,
-						"",
-					)
-					updater.episodeStarted = false
-				}
-				return playVideo(prevVideoURL, episodes, currentEpisodeNum-1, animeMalID, newUpdater)
-			} else {
-				fmt.Println("Already at the first episode.")
+			index, ok := AdjacentEpisodeIndex(currentEpisodeIndex, step, len(episodes))
+			if !ok {
+				fmt.Printf("Already at the %s episode.\n", edge)
+				continue
+			}
+			episode := episodes[index]
+			if updater != nil {
+				updater.Stop()
+			}
+			videoURL, err := AdjacentEpisodeVideoURL(episode.URL)
+			if err != nil {
+				fmt.Printf("Failed to get video URL for episode %d: %v\n", episode.Num, err)
+				continue
+			}
+			var newUpdater *RichPresenceUpdater
+			if updater != nil {
+				newUpdater = NewRichPresenceUpdater(
+					updater.anime,
+					updater.isPaused,
+					updater.animeMutex,
+					updater.updateFreq,
+					time.Duration(episode.Duration)*time.Second,
+					"",
+				)
+				updater.episodeStarted = false
 			}
+			stopTracking()
+			return playVideo(videoURL, episodes, index, animeURL, animeMalID, newUpdater)
 		case 'q': // Quit
 			fmt.Println("Quitting video playback.")
+			stopTracking()
 			_, _ = mpvSendCommand(socketPath, []interface{}{"quit"})
 			return nil
 		case 's': // Skip intro (OP)
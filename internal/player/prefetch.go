@@ -0,0 +1,143 @@
+package player
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/util"
+)
+
+// PrefetchWindow returns the indexes of the episodes that should be resolved ahead of the current one.
+// At most n indexes are returned and none of them goes past the end of the episode list.
+//
+// Parameters:
+// - current: the index of the episode being played.
+// - total: the number of episodes in the list.
+// - n: how many episodes to resolve ahead.
+//
+// Returns:
+// - []int: the indexes to prefetch, in playback order.
+func PrefetchWindow(current, total, n int) []int {
+	var window []int
+	for i := current + 1; i < total && len(window) < n; i++ {
+		window = append(window, i)
+	}
+	return window
+}
+
+// Prefetcher resolves the video URLs of upcoming episodes in the background,
+// so moving to the next episode doesn't wait on the scraping chain.
+// Prefetched URLs expire and are probed before use like the ones in StreamCache, since an episode is
+// usually prefetched a whole episode before it is played.
+type Prefetcher struct {
+	resolve  func(episodeURL string) (string, error)
+	probe    func(videoURL string) bool
+	ttl      time.Duration
+	mu       sync.Mutex
+	resolved map[string]cachedStream
+	inflight map[string]bool
+	wg       sync.WaitGroup
+}
+
+// NewPrefetcher creates a Prefetcher that uses resolve to turn an episode page URL into a video URL.
+// A prefetched URL is used for ttl, and only while probe still accepts it.
+func NewPrefetcher(resolve func(episodeURL string) (string, error), probe func(videoURL string) bool, ttl time.Duration) *Prefetcher {
+	return &Prefetcher{
+		resolve:  resolve,
+		probe:    probe,
+		ttl:      ttl,
+		resolved: make(map[string]cachedStream),
+		inflight: make(map[string]bool),
+	}
+}
+
+// episodePrefetcher is shared by every playVideo call of the session.
+var episodePrefetcher = NewPrefetcher(ResolveVideoURL, probeVideoURL, streamCacheTTL)
+
+// Schedule starts resolving the n episodes after current that are not resolved or being resolved yet.
+func (p *Prefetcher) Schedule(episodes []api.Episode, current, n int) {
+	for _, idx := range PrefetchWindow(current, len(episodes), n) {
+		episodeURL := episodes[idx].URL
+
+		p.mu.Lock()
+		_, done := p.resolved[episodeURL]
+		if done || p.inflight[episodeURL] {
+			p.mu.Unlock()
+			continue
+		}
+		p.inflight[episodeURL] = true
+		p.mu.Unlock()
+
+		p.wg.Add(1)
+		go func(episodeURL string) {
+			defer p.wg.Done()
+
+			videoURL, err := p.resolve(episodeURL)
+
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			delete(p.inflight, episodeURL)
+			if err != nil {
+				if util.CurrentConfig().Debug {
+					log.Printf("Prefetch failed for %s: %v", episodeURL, err)
+				}
+				return
+			}
+			p.resolved[episodeURL] = cachedStream{videoURL: videoURL, expiry: time.Now().Add(p.ttl)}
+		}(episodeURL)
+	}
+}
+
+// Wait blocks until every scheduled prefetch has finished.
+func (p *Prefetcher) Wait() {
+	p.wg.Wait()
+}
+
+// Prefetched returns the prefetched video URL for the episode while it is fresh and reachable.
+// An expired or unreachable URL is dropped, so the episode can be prefetched again.
+func (p *Prefetcher) Prefetched(episodeURL string) (string, bool) {
+	p.mu.Lock()
+	entry, ok := p.resolved[episodeURL]
+	p.mu.Unlock()
+	if !ok {
+		return "", false
+	}
+	if time.Now().Before(entry.expiry) && p.probe(entry.videoURL) {
+		return entry.videoURL, true
+	}
+
+	p.mu.Lock()
+	delete(p.resolved, episodeURL)
+	p.mu.Unlock()
+	return "", false
+}
+
+// VideoURL returns the prefetched video URL for the episode, resolving it now if it wasn't prefetched or has gone stale.
+func (p *Prefetcher) VideoURL(episodeURL string) (string, error) {
+	if videoURL, ok := p.Prefetched(episodeURL); ok {
+		return videoURL, nil
+	}
+	return p.resolve(episodeURL)
+}
+
+// Resolved reports how many episodes currently have a prefetched video URL.
+func (p *Prefetcher) Resolved() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.resolved)
+}
+
+// AdjacentEpisodeVideoURL resolves the episode moved to with next or previous, using its prefetched URL when there is one.
+// Prefetching never asks for the quality, so with -ask-quality a prefetched URL is only used when it is in the quality
+// chosen for the session; otherwise the episode is resolved like a selected one, asking when that quality isn't offered.
+func AdjacentEpisodeVideoURL(episodeURL string) (string, error) {
+	if !util.CurrentConfig().AskQuality {
+		return episodePrefetcher.VideoURL(episodeURL)
+	}
+	if videoURL, ok := episodePrefetcher.Prefetched(episodeURL); ok && inRememberedQuality(videoURL) {
+		return videoURL, nil
+	}
+	return GetVideoURLForEpisode(episodeURL)
+}
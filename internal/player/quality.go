@@ -0,0 +1,192 @@
+package player
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/manifoldco/promptui"
+)
+
+// GetAvailableQualities returns the display labels of videos, highest first.
+func GetAvailableQualities(videos []VideoData) []string {
+	labels := make([]string, 0, len(videos))
+	for _, video := range videos {
+		labels = append(labels, video.DisplayLabel())
+	}
+	sort.SliceStable(labels, func(i, j int) bool {
+		return qualityValue(labels[i]) > qualityValue(labels[j])
+	})
+	return labels
+}
+
+// qualityValue converts a label such as "720p", or "720p (HLS)", to 720, or 0 when the label has no number.
+func qualityValue(label string) int {
+	label = strings.TrimSuffix(label, " (HLS)")
+	value, _ := strconv.Atoi(strings.TrimRight(label, "p"))
+	return value
+}
+
+// QualitySelector picks the video quality of each episode and remembers the user's choice for the rest of the session.
+type QualitySelector struct {
+	prompt     func(labels []string) (string, error)
+	mu         sync.Mutex
+	remembered string
+}
+
+// NewQualitySelector creates a QualitySelector that asks the user with prompt when it needs a choice.
+func NewQualitySelector(prompt func(labels []string) (string, error)) *QualitySelector {
+	return &QualitySelector{prompt: prompt}
+}
+
+// Remembered returns the quality label chosen earlier in the session, or "" if none was chosen.
+func (q *QualitySelector) Remembered() string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.remembered
+}
+
+// Select returns the source URL of the quality to play.
+// The remembered quality is reused when the episode offers it. Otherwise, when ask is set the user is prompted
+// and the answer is remembered; when it isn't, the highest quality is used.
+//
+// Parameters:
+// - videos: the qualities offered for the episode.
+// - ask: whether the user may be prompted.
+//
+// Returns:
+// - string: the source URL of the selected quality.
+// - error: an error if no quality is available or the prompt fails.
+func (q *QualitySelector) Select(videos []VideoData, ask bool) (string, error) {
+	if len(videos) == 0 {
+		return "", errors.New("no video qualities available")
+	}
+
+	if remembered := q.Remembered(); remembered != "" {
+		for _, video := range videos {
+			if video.DisplayLabel() == remembered {
+				return video.Src, nil
+			}
+		}
+	}
+
+	if !ask || q.prompt == nil {
+		return selectHighestQualityVideo(videos), nil
+	}
+
+	// The lock isn't held while the user chooses, so background selections go on meanwhile
+	label, err := q.prompt(GetAvailableQualities(videos))
+	if err != nil {
+		return "", err
+	}
+	for _, video := range videos {
+		if video.DisplayLabel() == label {
+			q.mu.Lock()
+			q.remembered = label
+			q.mu.Unlock()
+			return video.Src, nil
+		}
+	}
+	return "", errors.New("selected quality is not available: " + label)
+}
+
+// promptQuality asks the user to choose one of the quality labels.
+func promptQuality(labels []string) (string, error) {
+	prompt := promptui.Select{
+		Label: "Select the video quality",
+		Items: labels,
+	}
+	_, result, err := prompt.Run()
+	return result, err
+}
+
+// sessionQuality remembers the quality chosen with -ask-quality for every episode of the session.
+var sessionQuality = NewQualitySelector(promptQuality)
+
+// SetQualityPrompt replaces the session's quality selector with one asking with prompt, forgetting the remembered
+// quality, and returns a function restoring the previous selector.
+func SetQualityPrompt(prompt func(labels []string) (string, error)) func() {
+	previous := sessionQuality
+	sessionQuality = NewQualitySelector(prompt)
+	return func() {
+		sessionQuality = previous
+	}
+}
+
+// inRememberedQuality reports whether videoURL was resolved in the quality the user chose for the session.
+func inRememberedQuality(videoURL string) bool {
+	remembered := sessionQuality.Remembered()
+	if remembered == "" {
+		return false
+	}
+	resolvedVideosMu.Lock()
+	defer resolvedVideosMu.Unlock()
+	video, ok := resolvedVideos[videoURL]
+	return ok && video.DisplayLabel() == remembered
+}
+
+// selectSessionQuality selects the quality of an episode being played, prompting when -ask-quality is set.
+func selectSessionQuality(videos []VideoData) (string, error) {
+	return sessionQuality.Select(videos, util.CurrentConfig().AskQuality)
+}
+
+// selectPrefetchQuality selects the quality of an episode resolved in the background, which must never prompt.
+func selectPrefetchQuality(videos []VideoData) (string, error) {
+	return sessionQuality.Select(videos, false)
+}
+
+var (
+	resolvedVideosMu sync.Mutex
+	resolvedVideos   = map[string]VideoData{}
+)
+
+// rememberVideoQuality records the quality label and container of the video resolved to videoURL,
+// for -quality-in-name and for choosing its downloader.
+func rememberVideoQuality(videos []VideoData, videoURL string) {
+	for _, video := range videos {
+		if video.Src == videoURL {
+			resolvedVideosMu.Lock()
+			resolvedVideos[videoURL] = video
+			resolvedVideosMu.Unlock()
+			return
+		}
+	}
+}
+
+// VideoQuality returns the quality label videoURL was resolved with, or "" when it isn't known, as with Blogger videos.
+func VideoQuality(videoURL string) string {
+	resolvedVideosMu.Lock()
+	defer resolvedVideosMu.Unlock()
+	return resolvedVideos[videoURL].Label
+}
+
+// resolvedAsHLS reports whether videoURL was resolved from an entry the site marked as HLS.
+func resolvedAsHLS(videoURL string) bool {
+	resolvedVideosMu.Lock()
+	defer resolvedVideosMu.Unlock()
+	video, ok := resolvedVideos[videoURL]
+	return ok && video.Container() == ContainerHLS
+}
+
+// QualityFilePath adds quality to an episode file name, e.g. "5.mp4" becomes "5.1080p.mp4", so downloads of
+// the same episode in different qualities don't overwrite each other. An unknown quality leaves the path as is.
+func QualityFilePath(episodePath, quality string) string {
+	quality = sanitizeFileName(quality)
+	if quality == "" {
+		return episodePath
+	}
+	ext := filepath.Ext(episodePath)
+	return strings.TrimSuffix(episodePath, ext) + "." + quality + ext
+}
+
+// episodeQualityPath applies QualityFilePath with the quality of videoURL when -quality-in-name is set.
+func episodeQualityPath(episodePath, videoURL string) string {
+	if !util.CurrentConfig().QualityInName {
+		return episodePath
+	}
+	return QualityFilePath(episodePath, VideoQuality(videoURL))
+}
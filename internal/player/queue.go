@@ -0,0 +1,31 @@
+package player
+
+import "github.com/alvarorichard/Goanime/internal/api"
+
+// AdjacentEpisodeIndex returns the index of the episode step places away from current in a queue of count
+// episodes, e.g. step -1 for the previous one. Moving before the first or after the last episode is refused:
+// the boolean is false and the index is clamped to the nearest end of the queue.
+func AdjacentEpisodeIndex(current, step, count int) (int, bool) {
+	target := current + step
+	switch {
+	case count == 0:
+		return 0, false
+	case target < 0:
+		return 0, false
+	case target >= count:
+		return count - 1, false
+	}
+	return target, true
+}
+
+// EpisodeIndex returns the index of the episode numbered num, as parsed into api.Episode.Num.
+// Labels are not looked at: an episode labelled "Episódio Final" is found by the number in its URL.
+// The boolean is false when no listed episode has that number.
+func EpisodeIndex(episodes []api.Episode, num int) (int, bool) {
+	for i, episode := range episodes {
+		if episode.Num == num {
+			return i, true
+		}
+	}
+	return -1, false
+}
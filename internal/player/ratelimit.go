@@ -0,0 +1,80 @@
+package player
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/util"
+)
+
+// RateLimiter is a token bucket over bytes. Every reader sharing a RateLimiter shares its rate,
+// so the parts of a multi-threaded download together stay under the cap.
+type RateLimiter struct {
+	bytesPerSecond float64
+	mu             sync.Mutex
+	tokens         float64
+	last           time.Time
+}
+
+// NewRateLimiter creates a RateLimiter allowing bytesPerSecond bytes per second.
+// The bucket starts empty and holds at most one second worth of bytes, so idle time never turns into a burst above the cap.
+func NewRateLimiter(bytesPerSecond float64) *RateLimiter {
+	return &RateLimiter{bytesPerSecond: bytesPerSecond, last: time.Now()}
+}
+
+// WaitN takes n bytes from the bucket, sleeping for as long as the bucket is in debt.
+func (l *RateLimiter) WaitN(n int) {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.bytesPerSecond
+	if l.tokens > l.bytesPerSecond {
+		l.tokens = l.bytesPerSecond
+	}
+	l.last = now
+	l.tokens -= float64(n)
+
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.bytesPerSecond * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	time.Sleep(wait)
+}
+
+// Reader wraps r so that reads from it are throttled by the limiter.
+func (l *RateLimiter) Reader(r io.Reader) io.Reader {
+	return &rateLimitedReader{r: r, limiter: l}
+}
+
+type rateLimitedReader struct {
+	r       io.Reader
+	limiter *RateLimiter
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.limiter.WaitN(n)
+	}
+	return n, err
+}
+
+var (
+	downloadLimiterOnce sync.Once
+	downloadLimiter     *RateLimiter
+)
+
+// throttle wraps r with the limiter shared by every download of the session when -max-rate is set.
+func throttle(r io.Reader) io.Reader {
+	downloadLimiterOnce.Do(func() {
+		if rate := util.CurrentConfig().MaxRate; rate > 0 {
+			downloadLimiter = NewRateLimiter(rate * 1024 * 1024)
+		}
+	})
+	if downloadLimiter == nil {
+		return r
+	}
+	return downloadLimiter.Reader(r)
+}
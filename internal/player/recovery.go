@@ -0,0 +1,57 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/alvarorichard/Goanime/internal/util"
+)
+
+// ErrCorruptDownload is returned when the file put together from the downloaded parts is not the episode.
+var ErrCorruptDownload = errors.New("combined download is corrupt")
+
+// CheckCombinedFile checks the file combineParts produced: it must be exactly expectedSize bytes, which catches
+// parts cut short by a failed thread. With -verify and ffprobe installed, the file must also probe as a playable episode.
+func CheckCombinedFile(path string, expectedSize int64) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCorruptDownload, err)
+	}
+	if info.Size() != expectedSize {
+		return fmt.Errorf("%w: %s is %d bytes, expected %d", ErrCorruptDownload, filepath.Base(path), info.Size(), expectedSize)
+	}
+
+	if util.CurrentConfig().Verify {
+		if _, err := exec.LookPath("ffprobe"); err == nil {
+			if err := probeFile(path); err != nil {
+				return fmt.Errorf("%w: %v", ErrCorruptDownload, err)
+			}
+		}
+	}
+	return nil
+}
+
+// RecoverCorruptDownload deletes a corrupt download, along with any part files left behind, and downloads the
+// episode once more with fallback, which is yt-dlp in DownloadVideo. The cause is kept in the error if that fails too.
+func RecoverCorruptDownload(destPath string, numThreads int, cause error, fallback func() error) error {
+	log.Printf("%v; downloading it again with yt-dlp\n", cause)
+
+	for part := 0; part < numThreads; part++ {
+		partPath := filepath.Join(filepath.Dir(destPath), fmt.Sprintf("%s.part%d", filepath.Base(destPath), part))
+		if err := os.Remove(partPath); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove %s: %v\n", partPath, err)
+		}
+	}
+	if err := os.Remove(destPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove corrupt download: %w", err)
+	}
+
+	if err := fallback(); err != nil {
+		return fmt.Errorf("yt-dlp fallback failed after %v: %w", cause, err)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package player
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/manifoldco/promptui"
+)
+
+// resumeSaveInterval is how often the playback position is saved, so a crash loses at most this much progress.
+const resumeSaveInterval = 5 * time.Second
+
+// ResumeFinishedMargin is how close to the end an episode must be stopped to count as watched; its position is
+// then forgotten instead of saved. It covers the ending credits and the next episode preview.
+const ResumeFinishedMargin = 90 * time.Second
+
+// ResumePositions remembers where playback stopped in each episode, so the next play starts from there.
+type ResumePositions struct {
+	Positions map[string]float64 `json:"positions"` // Seconds into the episode, by resumeKey
+
+	path string
+	mu   sync.Mutex
+}
+
+// ResumePositionsPath returns where the playback positions are saved.
+func ResumePositionsPath() (string, error) {
+	dataDir, err := util.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "resume.json"), nil
+}
+
+// LoadResumePositions reads the playback positions saved at path. A missing file means no positions were saved yet.
+func LoadResumePositions(path string) (*ResumePositions, error) {
+	positions := &ResumePositions{Positions: map[string]float64{}, path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return positions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, positions); err != nil {
+		return nil, fmt.Errorf("failed to read the playback positions %s: %w", path, err)
+	}
+	if positions.Positions == nil {
+		positions.Positions = map[string]float64{}
+	}
+	return positions, nil
+}
+
+// resumeKey identifies an episode in the saved positions.
+func resumeKey(animeURL string, episodeNum int) string {
+	return fmt.Sprintf("%s#%d", strings.TrimSuffix(animeURL, "/"), episodeNum)
+}
+
+// Position returns where playback of the episode stopped, in seconds. The boolean is false when nothing was saved.
+func (r *ResumePositions) Position(animeURL string, episodeNum int) (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	seconds, ok := r.Positions[resumeKey(animeURL, episodeNum)]
+	return seconds, ok
+}
+
+// Update records that playback of the episode reached position and saves the positions.
+// An episode stopped within ResumeFinishedMargin of its end is forgotten instead; with an unknown duration
+// (zero), the position is always kept.
+func (r *ResumePositions) Update(animeURL string, episodeNum int, position, duration time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := resumeKey(animeURL, episodeNum)
+	if duration > 0 && position >= duration-ResumeFinishedMargin {
+		if _, ok := r.Positions[key]; !ok {
+			return nil
+		}
+		delete(r.Positions, key)
+	} else {
+		r.Positions[key] = position.Seconds()
+	}
+	return r.save()
+}
+
+// save writes the positions to their file, replacing it atomically so a crash never leaves half a file.
+func (r *ResumePositions) save() error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), os.ModePerm); err != nil {
+		return err
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.path)
+}
+
+// ResumeArgs returns the mpv argument starting playback at the given number of seconds.
+func ResumeArgs(seconds float64) []string {
+	return []string{fmt.Sprintf("--start=%d", int(seconds))}
+}
+
+// FormatPosition renders a playback position as m:ss, or h:mm:ss past an hour.
+func FormatPosition(seconds float64) string {
+	total := int(seconds)
+	if total >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", total/3600, total/60%60, total%60)
+	}
+	return fmt.Sprintf("%d:%02d", total/60, total%60)
+}
+
+// loadSessionResume reads the saved playback positions, logging instead of failing playback when they can't be read.
+func loadSessionResume() *ResumePositions {
+	path, err := ResumePositionsPath()
+	if err == nil {
+		var positions *ResumePositions
+		if positions, err = LoadResumePositions(path); err == nil {
+			return positions
+		}
+	}
+	log.Printf("Playback positions are not available: %v\n", err)
+	return nil
+}
+
+// resumeStartArgs returns the mpv arguments resuming the episode where it was stopped last time.
+// The user is asked first, unless -resume is set.
+func resumeStartArgs(positions *ResumePositions, animeURL string, episodeNum int) []string {
+	if positions == nil || animeURL == "" {
+		return nil
+	}
+	seconds, ok := positions.Position(animeURL, episodeNum)
+	if !ok || seconds < 1 {
+		return nil
+	}
+	if !util.CurrentConfig().Resume && !askForResume(episodeNum, seconds) {
+		return nil
+	}
+	return ResumeArgs(seconds)
+}
+
+// askForResume asks whether to resume the episode at the saved position or start it over.
+func askForResume(episodeNum int, seconds float64) bool {
+	prompt := promptui.Select{
+		Label: fmt.Sprintf("Episode %d was stopped at %s", episodeNum, FormatPosition(seconds)),
+		Items: []string{"Resume", "Start over"},
+	}
+	_, result, err := prompt.Run()
+	if err != nil {
+		return false
+	}
+	return result == "Resume"
+}
+
+// trackResumePosition saves the playback position reported by mpv every resumeSaveInterval until the returned
+// function is called, which saves it one last time. Calling the function again does nothing.
+func trackResumePosition(positions *ResumePositions, socketPath, animeURL string, episodeNum int) func() {
+	if positions == nil || animeURL == "" {
+		return func() {}
+	}
+
+	save := func() {
+		position, err := mpvSendCommand(socketPath, []interface{}{"get_property", "time-pos"})
+		seconds, ok := position.(float64)
+		if err != nil || !ok {
+			// mpv is not playing yet, or already closed
+			return
+		}
+		var duration time.Duration
+		if length, err := mpvSendCommand(socketPath, []interface{}{"get_property", "duration"}); err == nil {
+			if lengthSeconds, ok := length.(float64); ok {
+				duration = time.Duration(lengthSeconds * float64(time.Second))
+			}
+		}
+		if err := positions.Update(animeURL, episodeNum, time.Duration(seconds*float64(time.Second)), duration); err != nil {
+			log.Printf("Failed to save the playback position: %v\n", err)
+		}
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(resumeSaveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				save()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+			save()
+		})
+	}
+}
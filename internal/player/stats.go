@@ -0,0 +1,50 @@
+package player
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/util"
+)
+
+// DownloadStats summarizes a finished download for the -stats flag.
+type DownloadStats struct {
+	Bytes     int64         // Total bytes written
+	Elapsed   time.Duration // Wall time of the download
+	PartBytes []int64       // Bytes written by each download thread
+}
+
+// Throughput returns the average download speed in MB/s.
+// It returns 0 when no time has elapsed.
+func (s DownloadStats) Throughput() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Bytes) / (1024 * 1024) / s.Elapsed.Seconds()
+}
+
+// String renders the stats as a short report: total size, time, speed and each thread's share.
+func (s DownloadStats) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Downloaded %.2f MB in %s (%.2f MB/s)",
+		float64(s.Bytes)/(1024*1024), s.Elapsed.Round(time.Millisecond), s.Throughput())
+
+	for i, partBytes := range s.PartBytes {
+		share := 0.0
+		if s.Bytes > 0 {
+			share = float64(partBytes) / float64(s.Bytes) * 100
+		}
+		fmt.Fprintf(&b, "\n  thread %d: %.2f MB (%.1f%%)", i, float64(partBytes)/(1024*1024), share)
+	}
+
+	return b.String()
+}
+
+// reportStats prints the stats of the download saved to destPath when -stats is set.
+func reportStats(destPath string, stats DownloadStats) {
+	if util.CurrentConfig().ShowStats {
+		fmt.Printf("%s: %s\n", filepath.Base(destPath), stats)
+	}
+}
@@ -0,0 +1,274 @@
+package player
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/util"
+)
+
+// growPollInterval is how often the server checks whether the download has written more data.
+const growPollInterval = 200 * time.Millisecond
+
+// GrowingFileServer serves a file over HTTP while it is still being downloaded, so mpv can start
+// playing before yt-dlp finishes. Reads past the current end of the file wait for more data
+// instead of ending the stream early.
+type GrowingFileServer struct {
+	paths []string // Candidate paths, e.g. the yt-dlp part file and the final file it is renamed to
+
+	mu   sync.Mutex
+	done bool
+	err  error
+	wait chan struct{}
+}
+
+// NewGrowingFileServer creates a server for a download that is written to one of paths.
+// Paths are tried in order, so list the final path before the temporary one.
+func NewGrowingFileServer(paths ...string) *GrowingFileServer {
+	return &GrowingFileServer{paths: paths, wait: make(chan struct{})}
+}
+
+// Finish marks the download as complete, with err set if it failed.
+func (s *GrowingFileServer) Finish(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return
+	}
+	s.done, s.err = true, err
+	close(s.wait)
+}
+
+// Done returns a channel closed once Finish has been called.
+func (s *GrowingFileServer) Done() <-chan struct{} {
+	return s.wait
+}
+
+// Err returns the error the download finished with.
+func (s *GrowingFileServer) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *GrowingFileServer) finished() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done
+}
+
+// open returns the first candidate path that exists, waiting for the download to create one.
+func (s *GrowingFileServer) open(r *http.Request) (*os.File, error) {
+	for {
+		for _, path := range s.paths {
+			if f, err := os.Open(path); err == nil {
+				return f, nil
+			}
+		}
+		if s.finished() {
+			return nil, os.ErrNotExist
+		}
+		select {
+		case <-r.Context().Done():
+			return nil, r.Context().Err()
+		case <-time.After(growPollInterval):
+		}
+	}
+}
+
+// waitForSize blocks until f holds more than offset bytes, returning the current size.
+// It returns early once the download is finished or the client goes away.
+func (s *GrowingFileServer) waitForSize(r *http.Request, f *os.File, offset int64) (int64, error) {
+	for {
+		info, err := f.Stat()
+		if err != nil {
+			return 0, err
+		}
+		if info.Size() > offset || s.finished() {
+			return info.Size(), nil
+		}
+		select {
+		case <-r.Context().Done():
+			return 0, r.Context().Err()
+		case <-time.After(growPollInterval):
+		}
+	}
+}
+
+// ServeHTTP serves the download. Once it is finished, the file is served by http.ServeContent with full range support.
+// While it is still growing:
+//   - a closed range ("bytes=a-b") waits until byte b is written and answers 206 with an unknown total length;
+//   - an open range ("bytes=a-") is streamed from a, following the download until it ends. There is no
+//     Content-Length, since the size isn't known yet; mpv's ffmpeg doesn't ask again after a short answer;
+//   - a request without a range, or the "bytes=0-" mpv always starts with, answers 200 the same way from the start.
+func (s *GrowingFileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f, err := s.open(r)
+	if err != nil {
+		http.Error(w, "download not available", http.StatusNotFound)
+		return
+	}
+	defer func(f *os.File) {
+		err := f.Close()
+		if err != nil {
+			log.Printf("Failed to close streamed file: %v\n", err)
+		}
+	}(f)
+
+	if s.finished() {
+		info, err := f.Stat()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		http.ServeContent(w, r, filepath.Base(f.Name()), info.ModTime(), f)
+		return
+	}
+
+	start, end, hasRange := parseByteRange(r.Header.Get("Range"))
+	if !hasRange || (start == 0 && end < 0) {
+		w.Header().Set("Content-Type", "video/mp4")
+		w.WriteHeader(http.StatusOK)
+		s.follow(w, r, f, 0)
+		return
+	}
+
+	waitFor := start
+	if end >= 0 {
+		waitFor = end
+	}
+	size, err := s.waitForSize(r, f, waitFor)
+	if err != nil {
+		return
+	}
+	if start >= size {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+		http.Error(w, "range not satisfiable", http.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+	if end < 0 {
+		// The range ends where the download does; what is written so far is the best last position to announce
+		w.Header().Set("Content-Type", "video/mp4")
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/*", start, size-1))
+		w.WriteHeader(http.StatusPartialContent)
+		s.follow(w, r, f, start)
+		return
+	}
+	if end >= size {
+		end = size - 1
+	}
+
+	w.Header().Set("Content-Type", "video/mp4")
+	w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/*", start, end))
+	w.Header().Set("Content-Length", strconv.FormatInt(end-start+1, 10))
+	w.WriteHeader(http.StatusPartialContent)
+	if _, err := io.Copy(w, io.NewSectionReader(f, start, end-start+1)); err != nil && util.CurrentConfig().Debug {
+		log.Printf("Streaming range %d-%d stopped: %v", start, end, err)
+	}
+}
+
+// follow copies f to w from offset, waiting for new data until the download is finished.
+func (s *GrowingFileServer) follow(w http.ResponseWriter, r *http.Request, f *os.File, offset int64) {
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := f.ReadAt(buf, offset)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return
+			}
+			offset += int64(n)
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err == io.EOF {
+			if s.finished() {
+				// Drain anything written between the last read and Finish
+				if info, statErr := f.Stat(); statErr == nil && info.Size() > offset {
+					continue
+				}
+				return
+			}
+			if _, err := s.waitForSize(r, f, offset); err != nil {
+				return
+			}
+			continue
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// parseByteRange parses a single "bytes=start-end" range. end is -1 for open ranges.
+func parseByteRange(header string) (start, end int64, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, -1, false
+	}
+	from, to, found := strings.Cut(spec, "-")
+	if !found {
+		return 0, -1, false
+	}
+	start, err := strconv.ParseInt(from, 10, 64)
+	if err != nil || start < 0 {
+		return 0, -1, false
+	}
+	if to == "" {
+		return start, -1, true
+	}
+	end, err = strconv.ParseInt(to, 10, 64)
+	if err != nil || end < start {
+		return 0, -1, false
+	}
+	return start, end, true
+}
+
+// streamWhileDownloading starts a yt-dlp download of videoURL and plays it from a local server while it downloads.
+// It returns after playback ends and the download has finished.
+func streamWhileDownloading(videoURL, episodePath string, play func(localURL string) error) error {
+	cmd := YtDlpCommand(episodePath, videoURL)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start yt-dlp: %w", err)
+	}
+
+	server := NewGrowingFileServer(episodePath, episodePath+".part")
+	go func() {
+		server.Finish(cmd.Wait())
+	}()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to start local stream server: %w", err)
+	}
+	httpServer := &http.Server{Handler: server}
+	go func() {
+		_ = httpServer.Serve(listener)
+	}()
+	defer func(httpServer *http.Server) {
+		_ = httpServer.Close()
+	}(httpServer)
+
+	localURL := fmt.Sprintf("http://%s/%s", listener.Addr(), filepath.Base(episodePath))
+	if util.CurrentConfig().Debug {
+		log.Printf("Streaming %s while downloading from %s", episodePath, localURL)
+	}
+
+	playErr := play(localURL)
+
+	fmt.Println("Waiting for the download to finish...")
+	<-server.Done()
+	if err := server.Err(); err != nil {
+		return fmt.Errorf("failed to download video using yt-dlp: %w", err)
+	}
+	return playErr
+}
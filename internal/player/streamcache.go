@@ -0,0 +1,75 @@
+package player
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/util"
+)
+
+// streamCacheTTL is how long a resolved video URL is reused. Stream URLs carry access tokens that expire,
+// so entries are short-lived and are probed again before every reuse.
+const streamCacheTTL = 20 * time.Minute
+
+// cachedStream is a resolved video URL and when it stops being reused.
+type cachedStream struct {
+	videoURL string
+	expiry   time.Time
+}
+
+// StreamCache remembers the video URL each episode resolved to, so replaying an episode or downloading
+// one that was just played doesn't walk the scraping chain again.
+type StreamCache struct {
+	resolve func(episodeURL string) (string, error)
+	probe   func(videoURL string) bool
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]cachedStream
+}
+
+// NewStreamCache creates a StreamCache that resolves episodes with resolve and keeps results for ttl.
+// A cached URL is only reused while probe still accepts it.
+func NewStreamCache(resolve func(episodeURL string) (string, error), probe func(videoURL string) bool, ttl time.Duration) *StreamCache {
+	return &StreamCache{resolve: resolve, probe: probe, ttl: ttl, entries: make(map[string]cachedStream)}
+}
+
+// VideoURL returns the cached video URL of an episode while it is fresh and reachable, resolving it again otherwise.
+func (c *StreamCache) VideoURL(episodeURL string) (string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[episodeURL]
+	c.mu.Unlock()
+
+	if ok && time.Now().Before(entry.expiry) && c.probe(entry.videoURL) {
+		if util.CurrentConfig().Debug {
+			log.Printf("Reusing the resolved video URL of %s", episodeURL)
+		}
+		return entry.videoURL, nil
+	}
+
+	videoURL, err := c.resolve(episodeURL)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	c.entries[episodeURL] = cachedStream{videoURL: videoURL, expiry: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+	return videoURL, nil
+}
+
+// probeVideoURL checks with a HEAD request that a cached video URL still answers.
+func probeVideoURL(videoURL string) bool {
+	resp, err := api.SafeHead(videoURL)
+	if err != nil {
+		return false
+	}
+	_ = resp.Body.Close()
+	// Some hosts refuse HEAD but still serve the video
+	return resp.StatusCode < http.StatusBadRequest || resp.StatusCode == http.StatusMethodNotAllowed
+}
+
+// sessionStreams caches the episodes resolved interactively during the session.
+var sessionStreams = NewStreamCache(resolveVideoURLForEpisode, probeVideoURL, streamCacheTTL)
@@ -0,0 +1,71 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoPlayableStream is matched, with errors.Is, by the error returned when every way of getting an episode's stream failed.
+var ErrNoPlayableStream = errors.New("no playable stream found")
+
+// Servers an AnimeFire episode can be streamed from, as recorded in a StreamAttempt.
+const (
+	ServerEpisodePage = "episode page" // The episode page itself, before any server is known
+	ServerPlayer      = "player"       // AnimeFire's own player, whose video JSON lists the qualities
+	ServerBlogger     = "Blogger"      // A Blogger embed, played as is
+)
+
+// StreamAttempt is one source, server and quality that was tried for an episode, and why it failed.
+type StreamAttempt struct {
+	Source  string
+	Server  string
+	Quality string // Empty when the attempt failed before a quality was chosen
+	Err     error
+}
+
+// String describes the attempt on one line, e.g. "AnimeFire, player, 720p: invalid video URL".
+func (a StreamAttempt) String() string {
+	parts := []string{a.Source, a.Server}
+	if a.Quality != "" {
+		parts = append(parts, a.Quality)
+	}
+	return fmt.Sprintf("%s: %v", strings.Join(parts, ", "), a.Err)
+}
+
+// NoPlayableStreamError lists every attempt made to get an episode's stream, in the order they were made.
+type NoPlayableStreamError struct {
+	Attempts []StreamAttempt
+}
+
+func (e *NoPlayableStreamError) Error() string {
+	tried := make([]string, 0, len(e.Attempts))
+	for _, attempt := range e.Attempts {
+		tried = append(tried, attempt.String())
+	}
+	return fmt.Sprintf("%v after %d attempt(s): %s", ErrNoPlayableStream, len(e.Attempts), strings.Join(tried, "; "))
+}
+
+// Is makes errors.Is(err, ErrNoPlayableStream) true.
+func (e *NoPlayableStreamError) Is(target error) bool {
+	return target == ErrNoPlayableStream
+}
+
+// Unwrap returns the error of every attempt, so errors.Is also finds what made them fail.
+func (e *NoPlayableStreamError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Attempts))
+	for _, attempt := range e.Attempts {
+		errs = append(errs, attempt.Err)
+	}
+	return errs
+}
+
+// Summary describes the failure for the user, one numbered line per attempt.
+func (e *NoPlayableStreamError) Summary() string {
+	var b strings.Builder
+	b.WriteString("No playable stream found. Tried:")
+	for i, attempt := range e.Attempts {
+		fmt.Fprintf(&b, "\n  %d. %s", i+1, attempt)
+	}
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package player
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PlaybackInfo describes what was picked for playback, for the line printed before it starts.
+type PlaybackInfo struct {
+	Anime   string
+	Episode int    // 0 for a movie
+	Source  string // e.g. api.SourceName
+	Quality string // Empty when unknown, as with Blogger videos
+	Audio   string // "sub" or "dub", see AudioLabel
+}
+
+// AudioLabel tells dubbed from subtitled anime by name; AnimeFire lists dubs as separate "(Dublado)" entries.
+func AudioLabel(animeName string) string {
+	if strings.Contains(strings.ToLower(animeName), "dublado") {
+		return "dub"
+	}
+	return "sub"
+}
+
+// PlaybackSummary renders info as one line, e.g. "Playing Naruto E5 from AnimeFire [720p, sub]",
+// so users, and the output they paste in bug reports, see what was selected.
+func PlaybackSummary(info PlaybackInfo) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Playing %s", info.Anime)
+	if info.Episode > 0 {
+		fmt.Fprintf(&b, " E%d", info.Episode)
+	}
+	if info.Source != "" {
+		fmt.Fprintf(&b, " from %s", info.Source)
+	}
+
+	var details []string
+	for _, detail := range []string{info.Quality, info.Audio} {
+		if detail != "" {
+			details = append(details, detail)
+		}
+	}
+	if len(details) > 0 {
+		fmt.Fprintf(&b, " [%s]", strings.Join(details, ", "))
+	}
+	return b.String()
+}
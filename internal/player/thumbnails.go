@@ -0,0 +1,116 @@
+package player
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/util"
+)
+
+// PosterPath returns where -thumbnails saves the cover image of the anime an episode belongs to:
+// "poster" next to the episode, with the image's own extension, which is where media servers look for it.
+func PosterPath(episodePath, imageURL string) string {
+	ext := ".jpg"
+	if parsed, err := url.Parse(imageURL); err == nil {
+		if e := strings.ToLower(path.Ext(parsed.Path)); e != "" {
+			ext = e
+		}
+	}
+	return filepath.Join(filepath.Dir(episodePath), "poster"+ext)
+}
+
+// FFmpegCoverArgs builds the ffmpeg arguments that copy episodePath to outputPath with posterPath as its cover art.
+// The streams are copied as they are; only the cover is re-encoded, since MP4 cover art must be JPEG or PNG
+// and AnimeFire serves WebP images.
+func FFmpegCoverArgs(episodePath, posterPath, outputPath string) []string {
+	return []string{
+		"-y", "-v", "error",
+		"-i", episodePath, "-i", posterPath,
+		"-map", "0", "-map", "1",
+		"-c", "copy", "-c:v:1", "mjpeg", "-disposition:v:1", "attached_pic",
+		outputPath,
+	}
+}
+
+// addThumbnail saves the anime's cover image next to a downloaded episode, once per folder, and embeds it in the
+// episode as cover art when ffmpeg is installed.
+func addThumbnail(episodePath, animeURL string) error {
+	imageURL, err := coverImageURL(animeURL)
+	if err != nil {
+		return fmt.Errorf("failed to find the cover image: %w", err)
+	}
+
+	posterPath := PosterPath(episodePath, imageURL)
+	if _, err := os.Stat(posterPath); os.IsNotExist(err) {
+		if err := downloadFile(imageURL, posterPath); err != nil {
+			return fmt.Errorf("failed to save the cover image: %w", err)
+		}
+	}
+
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		if util.CurrentConfig().Debug {
+			log.Println("ffmpeg not found, saving the cover image without embedding it")
+		}
+		return nil
+	}
+
+	coveredPath := episodePath + ".cover.mp4"
+	if output, err := newCommand("ffmpeg", FFmpegCoverArgs(episodePath, posterPath, coveredPath)...).CombinedOutput(); err != nil {
+		_ = os.Remove(coveredPath)
+		return fmt.Errorf("ffmpeg failed to embed the cover image: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return os.Rename(coveredPath, episodePath)
+}
+
+// coverImageURLs caches the cover image of each anime, so a batch download looks it up once.
+var coverImageURLs sync.Map
+
+// coverImageURL returns the URL of the cover image on the anime's page.
+func coverImageURL(animeURL string) (string, error) {
+	if imageURL, ok := coverImageURLs.Load(animeURL); ok {
+		return imageURL.(string), nil
+	}
+	anime := &api.Anime{URL: animeURL}
+	if err := api.FetchAnimeDetails(anime); err != nil {
+		return "", err
+	}
+	coverImageURLs.Store(animeURL, anime.ImageURL)
+	return anime.ImageURL, nil
+}
+
+// downloadFile saves the body of rawURL to destPath.
+func downloadFile(rawURL, destPath string) error {
+	resp, err := api.SafeGet(rawURL)
+	if err != nil {
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		if err := Body.Close(); err != nil {
+			log.Printf("Failed to close response body: %v\n", err)
+		}
+	}(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("request failed with status: %s", resp.Status)
+	}
+
+	file, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		_ = file.Close()
+		_ = os.Remove(destPath)
+		return err
+	}
+	return file.Close()
+}
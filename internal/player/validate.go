@@ -0,0 +1,28 @@
+package player
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ValidateVideoURL checks that a scraped video URL can be handed to mpv or yt-dlp.
+// It must be an absolute http(s) URL with a host, so a broken extraction fails here
+// with a clear message instead of as an obscure player error.
+func ValidateVideoURL(videoURL string) error {
+	if strings.TrimSpace(videoURL) == "" {
+		return fmt.Errorf("invalid video URL: the extracted URL is empty")
+	}
+
+	parsed, err := url.Parse(videoURL)
+	if err != nil {
+		return fmt.Errorf("invalid video URL %q: %w", videoURL, err)
+	}
+	if !parsed.IsAbs() || parsed.Host == "" {
+		return fmt.Errorf("invalid video URL %q: expected an absolute URL", videoURL)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("invalid video URL %q: unsupported scheme %q", videoURL, parsed.Scheme)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package player
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/util"
+)
+
+// minEpisodeDuration is the shortest download -verify accepts; anything shorter is a truncated or placeholder file.
+const minEpisodeDuration = 60 * time.Second
+
+// ProbeResult is what -verify learns about a downloaded file from ffprobe.
+type ProbeResult struct {
+	HasVideo bool
+	HasAudio bool
+	Duration time.Duration
+}
+
+// FFprobeArgs builds the ffprobe arguments that list a file's stream types and duration as JSON.
+func FFprobeArgs(path string) []string {
+	return []string{"-v", "error", "-show_entries", "stream=codec_type:format=duration", "-of", "json", path}
+}
+
+// ParseProbeOutput reads the JSON printed by ffprobe with FFprobeArgs.
+func ParseProbeOutput(data []byte) (ProbeResult, error) {
+	var output struct {
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(data, &output); err != nil {
+		return ProbeResult{}, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	var result ProbeResult
+	for _, stream := range output.Streams {
+		switch stream.CodecType {
+		case "video":
+			result.HasVideo = true
+		case "audio":
+			result.HasAudio = true
+		}
+	}
+	if seconds, err := strconv.ParseFloat(output.Format.Duration, 64); err == nil {
+		result.Duration = time.Duration(seconds * float64(time.Second))
+	}
+	return result, nil
+}
+
+// Validate reports why a probed file is not a playable episode, or nil if it is.
+func (r ProbeResult) Validate() error {
+	if !r.HasVideo {
+		if r.HasAudio {
+			return fmt.Errorf("the file is audio-only")
+		}
+		return fmt.Errorf("the file has no video stream")
+	}
+	if r.Duration < minEpisodeDuration {
+		return fmt.Errorf("the file is only %s long", r.Duration.Round(time.Second))
+	}
+	return nil
+}
+
+// verifyDownload probes a finished download with ffprobe when -verify is set.
+// A broken file is renamed to "<path>.broken" so it isn't taken for a finished episode, and an error is returned.
+// Without ffprobe on the PATH the check is skipped.
+func verifyDownload(episodePath string) error {
+	if !util.CurrentConfig().Verify {
+		return nil
+	}
+	if _, err := exec.LookPath("ffprobe"); err != nil {
+		log.Println("ffprobe not found, skipping -verify")
+		return nil
+	}
+
+	if err := probeFile(episodePath); err != nil {
+		if renameErr := os.Rename(episodePath, episodePath+".broken"); renameErr != nil {
+			log.Printf("Failed to set aside %s: %v\n", episodePath, renameErr)
+		}
+		return fmt.Errorf("downloaded file failed verification: %w", err)
+	}
+	return nil
+}
+
+// probeFile runs ffprobe on path and reports why it isn't a playable episode, or nil if it is.
+func probeFile(path string) error {
+	output, err := newCommand("ffprobe", FFprobeArgs(path)...).Output()
+	if err != nil {
+		return fmt.Errorf("ffprobe failed on %s: %w", path, err)
+	}
+	result, err := ParseProbeOutput(output)
+	if err != nil {
+		return err
+	}
+	return result.Validate()
+}
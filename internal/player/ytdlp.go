@@ -0,0 +1,149 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/util"
+)
+
+// ErrYtDlpNotFound is returned when a download needs yt-dlp and it is neither on PATH nor at -yt-dlp-path.
+var ErrYtDlpNotFound = errors.New("yt-dlp is not installed")
+
+// YtDlpMaxAge is how old a yt-dlp release may be before a warning is shown. The sites change often and
+// older releases tend to fail with exit code 1 where an up to date one works.
+const YtDlpMaxAge = 90 * 24 * time.Hour
+
+// ytDlpBinary is a yt-dlp binary found on this run, and its version.
+type ytDlpBinary struct {
+	path    string
+	version string
+	err     error
+}
+
+var (
+	ytDlpMu       sync.Mutex
+	ytDlpBinaries = map[string]ytDlpBinary{} // By the name or path it was looked up with
+)
+
+// resolveYtDlp returns the path of the yt-dlp binary, from -yt-dlp-path or else PATH.
+// The binary is only looked up, and its version checked, the first time it is needed on a run.
+func resolveYtDlp() (string, error) {
+	name := util.CurrentConfig().YtDlpPath
+	if name == "" {
+		name = "yt-dlp"
+	}
+
+	ytDlpMu.Lock()
+	defer ytDlpMu.Unlock()
+	if binary, ok := ytDlpBinaries[name]; ok {
+		return binary.path, binary.err
+	}
+
+	var binary ytDlpBinary
+	binary.path, binary.err = exec.LookPath(name)
+	if binary.err != nil {
+		binary.err = fmt.Errorf("%w (%v); install it or point -yt-dlp-path at it", ErrYtDlpNotFound, binary.err)
+	} else if out, err := exec.Command(binary.path, "--version").Output(); err == nil {
+		binary.version = strings.TrimSpace(string(out))
+		if warning := YtDlpVersionWarning(binary.version, time.Now()); warning != "" {
+			log.Println(warning)
+		}
+	}
+	if util.CurrentConfig().Debug && binary.err == nil {
+		log.Printf("Using yt-dlp %s at %s\n", binary.version, binary.path)
+	}
+	ytDlpBinaries[name] = binary
+	return binary.path, binary.err
+}
+
+// YtDlpReleaseDate reads the release date from a yt-dlp version such as "2024.08.06", or a nightly "2024.08.06.232903".
+// The boolean is false when the version doesn't start with a date.
+func YtDlpReleaseDate(version string) (time.Time, bool) {
+	parts := strings.SplitN(version, ".", 4)
+	if len(parts) < 3 {
+		return time.Time{}, false
+	}
+	date, err := time.Parse("2006.01.02", strings.Join(parts[:3], "."))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// YtDlpVersionWarning returns a warning telling the user to update yt-dlp when its release is older than
+// YtDlpMaxAge at now, or "" when it is recent or its date is unknown.
+func YtDlpVersionWarning(version string, now time.Time) string {
+	released, ok := YtDlpReleaseDate(version)
+	if !ok {
+		return ""
+	}
+	age := now.Sub(released)
+	if age <= YtDlpMaxAge {
+		return ""
+	}
+	return fmt.Sprintf("Warning: yt-dlp %s is %d days old; if downloads fail, update it with \"yt-dlp -U\" or your package manager", version, int(age.Hours()/24))
+}
+
+// YtDlpArgs builds the argument list used to download a video with yt-dlp.
+//
+// The output path is fixed by the episode, so an interrupted download always leaves the same
+// "<episodePath>.part" file behind and --continue picks it up on the next run. Part files are
+// kept on purpose: with --no-part a half-written episode would sit at episodePath and be taken
+// for a finished download.
+//
+// When -max-rate is set, it is passed on as --limit-rate; yt-dlp's "M" suffix is MiB, like -max-rate.
+// HLS streams are fetched -threads fragments at a time, since yt-dlp downloads them one by one by default.
+func YtDlpArgs(episodePath, videoURL string) []string {
+	args := []string{"--continue", "--no-progress"}
+	if threads := util.CurrentConfig().Threads; threads > 1 {
+		args = append(args, "--concurrent-fragments", strconv.Itoa(threads))
+	}
+	if rate := util.CurrentConfig().MaxRate; rate > 0 {
+		args = append(args, "--limit-rate", strconv.FormatFloat(rate, 'f', -1, 64)+"M")
+	}
+	return append(args, "-o", episodePath, videoURL)
+}
+
+// YtDlpCommand creates the yt-dlp command downloading videoURL to episodePath.
+// It runs the binary given with -yt-dlp-path, or the yt-dlp found on PATH.
+// When neither exists, running the command fails the way exec reports a missing program.
+func YtDlpCommand(episodePath, videoURL string) *exec.Cmd {
+	binary, err := resolveYtDlp()
+	if err != nil {
+		binary = util.CurrentConfig().YtDlpPath
+		if binary == "" {
+			binary = "yt-dlp"
+		}
+	}
+	return newCommand(binary, YtDlpArgs(episodePath, videoURL)...)
+}
+
+// downloadWithYtDlp downloads videoURL to episodePath with yt-dlp, resuming a previous partial download if there is one.
+// In debug mode yt-dlp's own output, including its resume messages, is shown.
+func downloadWithYtDlp(videoURL, episodePath string) error {
+	if _, err := resolveYtDlp(); err != nil {
+		return err
+	}
+	cmd := YtDlpCommand(episodePath, videoURL)
+	if util.CurrentConfig().Debug {
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+	}
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+	// yt-dlp reports its own progress, which GoAnime doesn't parse
+	if util.CurrentConfig().ShowStats {
+		fmt.Printf("%s: stats unavailable for yt-dlp downloads\n", filepath.Base(episodePath))
+	}
+	return nil
+}
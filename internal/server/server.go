@@ -0,0 +1,177 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/alvarorichard/Goanime/internal/util"
+)
+
+// requestTimeout bounds how long a single API request may spend scraping.
+const requestTimeout = 30 * time.Second
+
+// maxQueryLength is the longest search query accepted.
+const maxQueryLength = 100
+
+// sourceHost is the only site whose pages the API accepts, so the server can't be used to fetch arbitrary URLs.
+const sourceHost = "animefire.plus"
+
+// Stream is a resolved video, with any headers a player must send to fetch it.
+type Stream struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Client is what the API needs from the scrapers. It is an interface so the handlers can be tested without the network.
+type Client interface {
+	Search(ctx context.Context, query string) ([]api.Anime, error)
+	Episodes(ctx context.Context, animeURL string) ([]api.Episode, error)
+	Stream(ctx context.Context, episodeURL string) (Stream, error)
+}
+
+// New returns the HTTP handler serving /search, /episodes and /stream from client.
+func New(client Client) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if query == "" || len(query) > maxQueryLength {
+			writeError(w, http.StatusBadRequest, errors.New("q must be between 1 and 100 characters"))
+			return
+		}
+		serve(w, r, func(ctx context.Context) (interface{}, error) {
+			return client.Search(ctx, util.TreatingAnimeName(query))
+		})
+	})
+	mux.HandleFunc("/episodes", func(w http.ResponseWriter, r *http.Request) {
+		animeURL, err := sourceURLParam(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		serve(w, r, func(ctx context.Context) (interface{}, error) {
+			return client.Episodes(ctx, animeURL)
+		})
+	})
+	mux.HandleFunc("/stream", func(w http.ResponseWriter, r *http.Request) {
+		episodeURL, err := sourceURLParam(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		serve(w, r, func(ctx context.Context) (interface{}, error) {
+			return client.Stream(ctx, episodeURL)
+		})
+	})
+	return mux
+}
+
+// sourceURLParam returns the url query parameter, which must be an https page of the source site.
+func sourceURLParam(r *http.Request) (string, error) {
+	raw := r.URL.Query().Get("url")
+	parsed, err := url.Parse(raw)
+	if raw == "" || err != nil || parsed.Scheme != "https" {
+		return "", errors.New("url must be an https URL")
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if host != sourceHost && !strings.HasSuffix(host, "."+sourceHost) {
+		return "", errors.New("url must point to " + sourceHost)
+	}
+	return raw, nil
+}
+
+// serve runs fetch with a per-request context and writes its result as JSON.
+func serve(w http.ResponseWriter, r *http.Request, fetch func(ctx context.Context) (interface{}, error)) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, http.StatusMethodNotAllowed, errors.New("only GET is supported"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+	defer cancel()
+
+	result, err := fetch(ctx)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		writeError(w, http.StatusGatewayTimeout, err)
+	case errors.Is(err, api.ErrNoAnimeFound), errors.Is(err, api.ErrNoEpisodes):
+		writeError(w, http.StatusNotFound, err)
+	case err != nil:
+		writeError(w, http.StatusBadGateway, err)
+	default:
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Printf("Failed to write response: %v\n", err)
+	}
+}
+
+// scraperClient is the Client backed by the AnimeFire scraper.
+type scraperClient struct{}
+
+// NewScraperClient returns the Client used by goanime serve.
+func NewScraperClient() Client {
+	return scraperClient{}
+}
+
+// withContext runs fetch, giving up when ctx is done. The scrapers don't take a context, so an abandoned
+// fetch finishes in the background and its result is dropped.
+func withContext[T any](ctx context.Context, fetch func() (T, error)) (T, error) {
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := fetch()
+		done <- result{value, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.value, res.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+func (scraperClient) Search(ctx context.Context, query string) ([]api.Anime, error) {
+	return withContext(ctx, func() ([]api.Anime, error) {
+		return api.SearchAnimeResults(query)
+	})
+}
+
+func (scraperClient) Episodes(ctx context.Context, animeURL string) ([]api.Episode, error) {
+	return withContext(ctx, func() ([]api.Episode, error) {
+		return api.GetAnimeEpisodes(animeURL)
+	})
+}
+
+func (scraperClient) Stream(ctx context.Context, episodeURL string) (Stream, error) {
+	return withContext(ctx, func() (Stream, error) {
+		videoURL, err := player.ResolveVideoURL(episodeURL)
+		if err != nil {
+			return Stream{}, err
+		}
+		// AnimeFire streams play without extra headers, which is how mpv is started as well
+		return Stream{URL: videoURL}, nil
+	})
+}
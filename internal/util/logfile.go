@@ -0,0 +1,58 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	logFileMu sync.RWMutex
+	// fileLogger writes to the -log-file only, for debug lines that aren't shown on the terminal.
+	fileLogger *log.Logger
+)
+
+// OpenLogFile appends everything logged during this run to path, in addition to the terminal, for attaching to bug reports.
+// Debug lines written with Debugf reach the file even without -debug, so the file has the whole run.
+// The returned function closes the file and restores the previous log output.
+//
+// Parameters:
+// - path: the log file, created if it doesn't exist.
+//
+// Returns:
+// - func() error: stops writing to the file.
+// - error: an error if the file can't be opened.
+func OpenLogFile(path string) (func() error, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	fmt.Fprintf(file, "--- goanime %s, %s ---\n", strings.Join(os.Args[1:], " "), time.Now().Format(time.RFC3339))
+
+	previous := log.Writer()
+	log.SetOutput(io.MultiWriter(previous, file))
+	logFileMu.Lock()
+	fileLogger = log.New(file, "", log.Flags())
+	logFileMu.Unlock()
+
+	return func() error {
+		log.SetOutput(previous)
+		logFileMu.Lock()
+		fileLogger = nil
+		logFileMu.Unlock()
+		return file.Close()
+	}, nil
+}
+
+// logToFileOnly writes a line to the -log-file without showing it on the terminal.
+func logToFileOnly(format string, args ...interface{}) {
+	logFileMu.RLock()
+	defer logFileMu.RUnlock()
+	if fileLogger != nil {
+		fileLogger.Printf(format, args...)
+	}
+}
@@ -0,0 +1,31 @@
+package util
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+)
+
+// DataDir returns the folder GoAnime keeps its downloads and their state in.
+// It is $XDG_DATA_HOME/goanime when XDG_DATA_HOME is set to an absolute path, as the XDG Base Directory
+// specification requires, and ~/.local/goanime otherwise.
+func DataDir() (string, error) {
+	if dataHome := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dataHome) {
+		return filepath.Join(dataHome, "goanime"), nil
+	}
+
+	currentUser, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(currentUser.HomeDir, ".local", "goanime"), nil
+}
+
+// DownloadsDir returns the folder every download is saved under.
+func DownloadsDir() (string, error) {
+	dataDir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "downloads"), nil
+}
@@ -0,0 +1,41 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// StartProfile starts writing a pprof profile of the given mode ("cpu" or "mem") to path.
+// The returned function stops the profile and closes the file; for "mem" it is the moment the heap profile is taken.
+func StartProfile(mode, path string) (func() error, error) {
+	if mode != "cpu" && mode != "mem" {
+		return nil, fmt.Errorf("unknown profile mode %q, expected cpu or mem", mode)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create profile file: %w", err)
+	}
+
+	if mode == "cpu" {
+		if err := pprof.StartCPUProfile(file); err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		return func() error {
+			pprof.StopCPUProfile()
+			return file.Close()
+		}, nil
+	}
+
+	return func() error {
+		runtime.GC() // Get up-to-date statistics for the heap profile
+		if err := pprof.WriteHeapProfile(file); err != nil {
+			_ = file.Close()
+			return fmt.Errorf("failed to write memory profile: %w", err)
+		}
+		return file.Close()
+	}, nil
+}
@@ -0,0 +1,106 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// ReportStep is one operation run by -report-source and its outcome.
+type ReportStep struct {
+	Description string
+	Err         string // Sanitized error, empty when the step succeeded
+}
+
+// IssueReport collects what -report-source needs to print a prefilled GitHub issue.
+type IssueReport struct {
+	Command string // The command line to reproduce the report
+	OS      string
+	Version string
+	Go      string
+	Source  string
+	Steps   []ReportStep
+}
+
+// NewIssueReport creates a report for command, filling in the environment details.
+func NewIssueReport(command, source string) *IssueReport {
+	version := "unknown"
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		version = info.Main.Version
+	}
+	return &IssueReport{
+		Command: command,
+		OS:      runtime.GOOS + "/" + runtime.GOARCH,
+		Version: version,
+		Go:      runtime.Version(),
+		Source:  source,
+	}
+}
+
+// AddStep records the outcome of an operation and reports whether it succeeded.
+func (r *IssueReport) AddStep(description string, err error) bool {
+	step := ReportStep{Description: description}
+	if err != nil {
+		step.Err = SanitizeReportText(err.Error())
+	}
+	r.Steps = append(r.Steps, step)
+	return err == nil
+}
+
+// Failed returns the first failing step, if any.
+func (r *IssueReport) Failed() (ReportStep, bool) {
+	for _, step := range r.Steps {
+		if step.Err != "" {
+			return step, true
+		}
+	}
+	return ReportStep{}, false
+}
+
+// Markdown renders the report as the body of a GitHub issue.
+func (r *IssueReport) Markdown() string {
+	var b strings.Builder
+
+	b.WriteString("### Describe the bug\n")
+	if failed, ok := r.Failed(); ok {
+		fmt.Fprintf(&b, "%s failed.\n", failed.Description)
+	} else {
+		b.WriteString("Every step succeeded; describe what went wrong.\n")
+	}
+
+	b.WriteString("\n### Steps to reproduce\n")
+	fmt.Fprintf(&b, "1. Run `%s`\n", r.Command)
+	for i, step := range r.Steps {
+		outcome := "ok"
+		if step.Err != "" {
+			outcome = "failed"
+		}
+		fmt.Fprintf(&b, "%d. %s: %s\n", i+2, step.Description, outcome)
+	}
+
+	if failed, ok := r.Failed(); ok {
+		fmt.Fprintf(&b, "\n### Error\n```\n%s\n```\n", failed.Err)
+	}
+
+	b.WriteString("\n### Environment\n")
+	fmt.Fprintf(&b, "- OS: %s\n", r.OS)
+	fmt.Fprintf(&b, "- GoAnime version: %s\n", r.Version)
+	fmt.Fprintf(&b, "- Go version: %s\n", r.Go)
+	fmt.Fprintf(&b, "- Source: %s\n", r.Source)
+	return b.String()
+}
+
+// queryValueRe matches URL query values, which carry stream access tokens.
+var queryValueRe = regexp.MustCompile(`([?&][^=\s&"']+=)[^&\s"']+`)
+
+// SanitizeReportText hides URL query values and the user's home directory, so a report can be posted publicly.
+func SanitizeReportText(text string) string {
+	text = queryValueRe.ReplaceAllString(text, "${1}REDACTED")
+	if home, err := os.UserHomeDir(); err == nil && home != "" && home != "/" {
+		text = strings.ReplaceAll(text, home, "~")
+	}
+	return text
+}
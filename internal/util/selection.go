@@ -0,0 +1,56 @@
+package util
+
+import (
+	"context"
+	"time"
+)
+
+// selectionCloseWait is how long FindWithContext waits for a cancelled finder to restore the terminal.
+const selectionCloseWait = time.Second
+
+// SelectionContext returns the context interactive selections run under, which ends after -select-timeout
+// when it is set so a prompt left unattended doesn't keep GoAnime running forever.
+func SelectionContext() (context.Context, context.CancelFunc) {
+	if timeout := CurrentConfig().SelectTimeout; timeout > 0 {
+		return context.WithTimeout(context.Background(), timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// FindWithContext runs an interactive selection, such as a fuzzy finder, and returns its result, or ctx.Err()
+// as soon as ctx is done. find gets a context it should close itself on (fuzzyfinder.WithContext); if it doesn't
+// within a second, it is left behind so the caller isn't blocked.
+//
+// Parameters:
+// - ctx: ends the selection when it is cancelled or times out.
+// - find: runs the selection and returns the chosen index.
+//
+// Returns:
+// - int: the chosen index, or -1 when ctx ended first.
+// - error: the selection's error, or ctx.Err().
+func FindWithContext(ctx context.Context, find func(ctx context.Context) (int, error)) (int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		idx int
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		idx, err := find(ctx)
+		done <- result{idx, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.idx, r.err
+	case <-ctx.Done():
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(selectionCloseWait):
+		}
+		return -1, ctx.Err()
+	}
+}
@@ -0,0 +1,50 @@
+package util
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+)
+
+// traceIDKey is the context key the trace ID of an operation is stored under.
+type traceIDKey struct{}
+
+// NewTraceID returns a short random ID, e.g. "3f9a1c07".
+func NewTraceID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "00000000"
+	}
+	return hex.EncodeToString(b)
+}
+
+// WithTrace starts a traced operation, such as a search, an episode resolution or a download.
+// Debug logs written with Debugf and the requests made with the returned context carry the same ID,
+// so the lines of concurrent operations can be told apart. A context that is already traced is returned as is,
+// which keeps nested operations under their caller's ID.
+func WithTrace(ctx context.Context) context.Context {
+	if TraceID(ctx) != "" {
+		return ctx
+	}
+	return context.WithValue(ctx, traceIDKey{}, NewTraceID())
+}
+
+// TraceID returns the trace ID of ctx, or "" when the context isn't traced.
+func TraceID(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDKey{}).(string)
+	return id
+}
+
+// Debugf logs a message in debug mode, prefixed with the trace ID of ctx when there is one.
+// Outside debug mode the message still goes to the -log-file, if there is one.
+func Debugf(ctx context.Context, format string, args ...interface{}) {
+	if id := TraceID(ctx); id != "" {
+		format = "[trace " + id + "] " + format
+	}
+	if !CurrentConfig().Debug {
+		logToFileOnly(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
@@ -5,17 +5,142 @@ import (
 	"fmt"
 	"github.com/manifoldco/promptui"
 	"os"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
+var minNameLength = 4
+
+// Search result selection modes accepted by -auto-select.
+const (
+	AutoSelectExact = "exact" // Skip the prompt when one result's name equals the query
+	AutoSelectTop   = "top"   // Always take the best matching result
+	AutoSelectOff   = "off"   // Always ask
+)
+
+// Audio modes accepted by -mode. Without -mode, dubbed and subtitled results are listed together.
+const (
+	ModeSub = "sub" // Subtitled, in Japanese
+	ModeDub = "dub" // Dubbed in Portuguese, AnimeFire's "(Dublado)" entries
+)
+
+// DefaultMaxEpisodes is how many episodes a batch download may queue before asking for confirmation.
+const DefaultMaxEpisodes = 50
+
+// Subtitle styling mpv uses on its own, which -sub-scale and -sub-pos leave alone.
+const (
+	DefaultSubScale = 1.0 // Subtitles at their normal size
+	DefaultSubPos   = 100 // Subtitles at the bottom of the screen
+)
+
+// Default timeouts of the connections made to the sites and video hosts.
+const (
+	DefaultConnectTimeout = 10 * time.Second // Dialing and the TLS handshake
+	DefaultHeaderTimeout  = 30 * time.Second // Waiting for the response headers
+)
+
+// DefaultResolveTimeout bounds the scraping of one episode's video URL, so a stuck site fails fast.
+const DefaultResolveTimeout = time.Minute
+
+// DefaultThreads is how many parts of a video, or HLS fragments with yt-dlp, are downloaded at once.
+const DefaultThreads = 4
+
+// MPV profiles accepted by -mpv-profile.
+const (
+	MPVProfileDefault  = "default"  // Use the user's mpv configuration
+	MPVProfileIsolated = "isolated" // Ignore the user's mpv configuration
+)
+
+// Config holds the settings chosen on the command line.
+// It is read with CurrentConfig and replaced as a whole with SetConfig, so concurrent readers always see a consistent snapshot.
+type Config struct {
+	Debug               bool
+	PrintCommand        bool
+	RedactCommand       bool
+	PrefetchCount       int
+	ShowStats           bool
+	AcceptLanguage      string
+	AllowedHosts        []string
+	AllowPrivateIPs     bool // No flag: lets tests reach servers on 127.0.0.1, must stay false otherwise
+	EpisodeTitles       bool
+	Since               time.Time
+	KeepUndated         bool
+	OnlyNew             bool
+	ResumeBatch         bool
+	Resume              bool
+	StreamWhileDownload bool
+	AskQuality          bool
+	OnComplete          string
+	MaxRate             float64
+	Profile             string
+	HostConcurrency     map[string]int
+	MPVProfile          string
+	SubScale            float64
+	SubColor            string
+	SubPos              int
+	EpisodeTitle        string
+	Dedupe              bool
+	ReportSource        bool
+	Verify              bool
+	OutputTemplate      string
+	Latest              bool
+	MaxEpisodes         int
+	Yes                 bool
+	Threads             int
+	AutoSelect          string
+	Mode                string
+	SearchOnly          bool
+	EpisodesInfo        bool
+	JSON                bool
+	NoFallbackOpen      bool
+	LogFile             string
+	QualityInName       bool
+	Thumbnails          bool
+	PreferMP4           bool
+	YtDlpPath           string
+	SelectTimeout       time.Duration
+	MaxRedirects        int
+	ConnectTimeout      time.Duration
+	HeaderTimeout       time.Duration
+	ResolveTimeout      time.Duration
+}
+
+// DefaultConfig returns the settings used when no flags are given.
+func DefaultConfig() Config {
+	return Config{MaxRedirects: 10, ConnectTimeout: DefaultConnectTimeout, HeaderTimeout: DefaultHeaderTimeout, ResolveTimeout: DefaultResolveTimeout, MPVProfile: MPVProfileDefault, SubScale: DefaultSubScale, SubPos: DefaultSubPos, MaxEpisodes: DefaultMaxEpisodes, Threads: DefaultThreads, AutoSelect: AutoSelectExact}
+}
+
 var (
-	IsDebug       bool
-	minNameLength = 4
+	configMu      sync.RWMutex
+	currentConfig = DefaultConfig()
 )
 
+// CurrentConfig returns a copy of the active settings. Changing the copy does not affect other readers.
+func CurrentConfig() Config {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return currentConfig
+}
+
+// SetConfig replaces the active settings and returns a function restoring the previous ones.
+func SetConfig(c Config) func() {
+	configMu.Lock()
+	previous := currentConfig
+	currentConfig = c
+	configMu.Unlock()
+	return func() {
+		configMu.Lock()
+		currentConfig = previous
+		configMu.Unlock()
+	}
+}
+
 // ErrorHandler returns a string with the error message, if debug mode is enabled, it will return the full error with details.
 func ErrorHandler(err error) string {
-	if IsDebug {
+	if CurrentConfig().Debug {
 		return fmt.Sprintf("%+v", err)
 	} else {
 		return fmt.Sprintf("%v -- run the program with -debug to see details", err)
@@ -28,9 +153,57 @@ func Helper() {
 	goanime 
 	goanime [options]
 	goanime [options] [anime name] (don't use - in the anime name, use spaces instead, e.g: "one piece" instead of "one-piece")
+	goanime [options] [anime name] latest (play the highest-numbered episode without choosing one)
+	goanime serve [-addr host:port] (serve a JSON API with /search?q=, /episodes?url= and /stream?url=, default address 127.0.0.1:8080)
 
 	Options:
 	   -debug: run the program in debug mode, which will show more details about errors and other information.
+	   -print-command: print the exact mpv/yt-dlp command before running it, useful for bug reports.
+	   -redact: used with -print-command, hides the host of the stream URL in the printed command.
+	   -prefetch N: resolve the next N episodes in the background while the current one plays.
+	   -stats: after each download, print the bytes transferred, elapsed time, average speed and per-thread share.
+	   -lang: Accept-Language sent to the sites, e.g. -lang pt-BR or -lang "en-US,en;q=0.8".
+	   -allow-hosts: comma-separated list of hosts (and their subdomains) GoAnime may connect to when following scraped URLs.
+	   -auto-select exact|top|off: skip the search result prompt when exactly one result has the name searched for (exact, the default), always take the best match (top), or always ask (off). Without a terminal, exact behaves like top.
+	   -mode sub|dub: only list the subtitled or the dubbed ("Dublado") versions of the anime searched for; when the site has no version in that mode, every result is listed.
+	   -dub: same as -mode dub.
+	   -episode-titles: fetch episode titles and air dates from MyAnimeList and show them in the episode list.
+	   -episode-title "<title>": play the episode whose title matches, e.g. -episode-title "finale" (fetches titles like -episode-titles).
+	   -max-redirects N: follow at most N redirects when fetching pages and videos (default 10); hops are logged in debug mode.
+	   -since YYYY-MM-DD: only list, play and download the episodes that aired on or after this date, e.g. -since 2025-01-01. Air dates come from MyAnimeList.
+	   -keep-undated: used with -since, also keep the episodes MyAnimeList has no air date for.
+	   -only-new: download every episode newer than the last one already downloaded, then exit.
+	   -resume-batch: finish the last batch download that was interrupted, without searching again, then exit.
+	   -resume: continue episodes from where you stopped watching them without asking (by default GoAnime asks).
+	   -stream-while-download: when downloading an episode with yt-dlp, start playing it before the download finishes.
+	   -ask-quality: ask which video quality to play instead of picking the highest; the choice is reused for the next episodes.
+	   -threads N: download N parts of each episode at once, also used as yt-dlp's --concurrent-fragments for HLS streams (default 4).
+	   -max-rate N: cap the download speed at N MB/s across all download threads, e.g. -max-rate 1.5.
+	   -max-episodes N: ask before a batch download of more than N episodes, and refuse with -only-new unless -yes is given (default 50, 0 disables the check).
+	   -yes: download batches larger than -max-episodes without asking.
+	   -host-concurrency: parallel download connections allowed per host, e.g. -host-concurrency "animefire.plus=1,example.com=3".
+	   -yt-dlp-path <path>: download with this yt-dlp binary instead of the one on your PATH, e.g. -yt-dlp-path /usr/bin/yt-dlp.
+	   -prefer-mp4: pick direct MP4 videos over HLS streams even when the MP4 has a lower quality, for connections where HLS keeps failing.
+	   -quality-in-name: add the video quality to downloaded file names when it is known, e.g. 5.1080p.mp4, so different qualities of an episode are kept side by side.
+	   -output-template "<template>": name downloads after a template relative to the "anime" folder inside the downloads folder, using {anime}, {season}, {episode}, {title} and {ext}, e.g. "{anime}/{anime} - S{season:02d}E{episode:02d} - {title}.{ext}" (-only-new only recognizes the default naming).
+	   -thumbnails: save the anime's cover image as poster.<ext> next to its downloads and, with ffmpeg installed, embed it in each episode as cover art.
+	   -verify: check each download with ffprobe (if installed) and set aside audio-only, video-less or truncated files as <file>.broken.
+	   -dedupe: hardlink episodes identical to one already downloaded under another anime folder instead of storing them twice.
+	   -no-fallback-open: fail when mpv is not installed instead of opening the episode with the system's default handler (xdg-open, open or start).
+	   -sub-scale <factor>: make mpv's subtitles bigger or smaller, e.g. -sub-scale 1.5 (default: 1).
+	   -sub-color <color>: color of mpv's subtitles, e.g. -sub-color "#FFFF00".
+	   -sub-pos <0-150>: vertical position of mpv's subtitles in percent of the screen height, e.g. -sub-pos 90 (default: 100, the bottom).
+	   -mpv-profile isolated: run mpv without your mpv.conf, input.conf and scripts, in case they break playback (default: use them).
+	   -on-complete "<cmd>": run a command after each episode download, with {file}, {episode} and {anime} replaced, e.g. -on-complete "mv {file} /media/{anime}-{episode}.mp4".
+	   -search-only: print the search results, best match first, with their source and URL, then exit.
+	   -episodes-info: print every episode of the anime with its number, title, duration and filler/recap flags, then exit.
+	   -json: used with -search-only or -episodes-info, print the results as JSON.
+	   -log-file <path>: also append the whole log of this run, including the debug lines of -debug, to a file you can attach to bug reports.
+	   -report-source: search for the anime, list its episodes and resolve the first one without prompting, then print a prefilled GitHub issue describing what failed.
+	   -connect-timeout <duration>: give up connecting to a site or video host after this long, e.g. -connect-timeout 5s (default: 10s).
+	   -header-timeout <duration>: give up on a server that takes longer than this to start answering, e.g. -header-timeout 1m (default: 30s). Slow downloads that keep receiving data are never cut off.
+	   -resolve-timeout <duration>: give up finding the video of an episode after this long, e.g. -resolve-timeout 2m (default: 1m). Downloads themselves have no time limit.
+	   -select-timeout <duration>: give up on the anime and episode pickers when nothing is chosen in time, e.g. -select-timeout 10m (default: wait forever).
 	   -help; -h; show this help message.
 	`)
 }
@@ -41,6 +214,54 @@ func FlagParser() (string, error) {
 	debug := flag.Bool("debug", false, "enable debug mode")
 	help := flag.Bool("help", false, "show help message")
 	altHelp := flag.Bool("h", false, "show help message")
+	printCommand := flag.Bool("print-command", false, "print the mpv/yt-dlp command before running it")
+	redact := flag.Bool("redact", false, "hide the stream host in printed commands")
+	prefetch := flag.Int("prefetch", 0, "number of episodes to resolve ahead while playing")
+	stats := flag.Bool("stats", false, "print download throughput and timing")
+	lang := flag.String("lang", "", "Accept-Language header sent to the sites")
+	allowHosts := flag.String("allow-hosts", "", "comma-separated hosts allowed when following scraped URLs")
+	episodeTitles := flag.Bool("episode-titles", false, "fetch episode titles from MyAnimeList")
+	episodeTitle := flag.String("episode-title", "", "play the episode whose title matches")
+	maxRedirects := flag.Int("max-redirects", DefaultConfig().MaxRedirects, "maximum number of redirects to follow")
+	resumeBatch := flag.Bool("resume-batch", false, "finish the last interrupted batch download")
+	resume := flag.Bool("resume", false, "resume episodes from the saved position without asking")
+	since := flag.String("since", "", "only keep episodes aired on or after this date (YYYY-MM-DD)")
+	keepUndated := flag.Bool("keep-undated", false, "keep episodes without an air date with -since")
+	onlyNew := flag.Bool("only-new", false, "download the episodes newer than the last downloaded one")
+	streamWhileDownload := flag.Bool("stream-while-download", false, "play yt-dlp downloads while they are downloading")
+	askQuality := flag.Bool("ask-quality", false, "ask which video quality to play")
+	onComplete := flag.String("on-complete", "", "command to run after each episode download")
+	autoSelect := flag.String("auto-select", AutoSelectExact, "pick a search result without asking: exact, top or off")
+	mode := flag.String("mode", "", "list only subtitled (sub) or dubbed (dub) search results")
+	dub := flag.Bool("dub", false, "same as -mode dub")
+	threads := flag.Int("threads", DefaultThreads, "parallel download connections per episode")
+	maxRate := flag.Float64("max-rate", 0, "maximum download speed in MB/s")
+	connectTimeout := flag.Duration("connect-timeout", DefaultConnectTimeout, "connection timeout")
+	headerTimeout := flag.Duration("header-timeout", DefaultHeaderTimeout, "response header timeout")
+	resolveTimeout := flag.Duration("resolve-timeout", DefaultResolveTimeout, "time limit for finding an episode's video")
+	selectTimeout := flag.Duration("select-timeout", 0, "give up on selection prompts after this long")
+	logFile := flag.String("log-file", "", "append the log of this run to a file")
+	searchOnly := flag.Bool("search-only", false, "print the search results and exit")
+	episodesInfo := flag.Bool("episodes-info", false, "print the episode list and exit")
+	jsonOutput := flag.Bool("json", false, "print -search-only and -episodes-info results as JSON")
+	reportSource := flag.Bool("report-source", false, "print a prefilled bug report for the anime's source")
+	ytDlpPath := flag.String("yt-dlp-path", "", "yt-dlp binary to download with")
+	preferMP4 := flag.Bool("prefer-mp4", false, "prefer MP4 videos over HLS streams")
+	qualityInName := flag.Bool("quality-in-name", false, "add the video quality to downloaded file names")
+	outputTemplate := flag.String("output-template", "", "file name template for downloads")
+	thumbnails := flag.Bool("thumbnails", false, "save and embed the anime cover in downloads")
+	verify := flag.Bool("verify", false, "check downloads with ffprobe")
+	dedupe := flag.Bool("dedupe", false, "hardlink identical downloads instead of storing them twice")
+	noFallbackOpen := flag.Bool("no-fallback-open", false, "don't open episodes with the system handler when mpv is missing")
+	subScale := flag.Float64("sub-scale", DefaultSubScale, "mpv subtitle size factor")
+	subColor := flag.String("sub-color", "", "mpv subtitle color")
+	subPos := flag.Int("sub-pos", DefaultSubPos, "mpv subtitle position, 0 (top) to 150")
+	mpvProfile := flag.String("mpv-profile", MPVProfileDefault, "mpv configuration to use: default or isolated")
+	maxEpisodes := flag.Int("max-episodes", DefaultMaxEpisodes, "confirm batch downloads of more than N episodes")
+	yes := flag.Bool("yes", false, "skip the -max-episodes confirmation")
+	hostConcurrency := flag.String("host-concurrency", "", "comma-separated host=N parallel download connection limits")
+	// Not listed in Helper: -profile is for maintainers investigating slow downloads
+	profile := flag.String("profile", "", "write a cpu or mem pprof profile to goanime-<mode>.pprof")
 
 	// Parse the flags early before any manipulation of os.Args
 	flag.Parse()
@@ -50,28 +271,210 @@ func FlagParser() (string, error) {
 		os.Exit(0)
 	}
 
-	IsDebug = *debug
+	config := DefaultConfig()
+	config.Debug = *debug
+	config.PrintCommand = *printCommand
+	config.RedactCommand = *redact
+	if *prefetch < 0 {
+		return "", fmt.Errorf("prefetch must not be negative, you entered: %d", *prefetch)
+	}
+	config.PrefetchCount = *prefetch
+	config.ShowStats = *stats
+	config.AcceptLanguage = *lang
+	config.EpisodeTitles = *episodeTitles
+	config.EpisodeTitle = *episodeTitle
+	if *maxRedirects < 0 {
+		return "", fmt.Errorf("max-redirects must not be negative, you entered: %d", *maxRedirects)
+	}
+	config.MaxRedirects = *maxRedirects
+	config.OnlyNew = *onlyNew
+	if *since != "" {
+		date, err := time.Parse(time.DateOnly, *since)
+		if err != nil {
+			return "", fmt.Errorf("since must be a date like 2025-01-01, you entered: %s", *since)
+		}
+		config.Since = date
+	}
+	config.KeepUndated = *keepUndated
+	config.ResumeBatch = *resumeBatch
+	config.Resume = *resume
+	if *maxEpisodes < 0 {
+		return "", fmt.Errorf("max-episodes must not be negative, you entered: %d", *maxEpisodes)
+	}
+	config.MaxEpisodes = *maxEpisodes
+	config.Yes = *yes
+	config.StreamWhileDownload = *streamWhileDownload
+	config.AskQuality = *askQuality
+	config.OnComplete = *onComplete
+	if *maxRate < 0 {
+		return "", fmt.Errorf("max-rate must not be negative, you entered: %v", *maxRate)
+	}
+	config.MaxRate = *maxRate
+	if *threads < 1 {
+		return "", fmt.Errorf("threads must be at least 1, you entered: %d", *threads)
+	}
+	config.Threads = *threads
+	switch *autoSelect {
+	case AutoSelectExact:
+		// Nobody can answer the prompt when running from a script
+		if !IsInteractive() {
+			*autoSelect = AutoSelectTop
+		}
+	case AutoSelectTop, AutoSelectOff:
+	default:
+		return "", fmt.Errorf("auto-select must be %s, %s or %s, you entered: %s", AutoSelectExact, AutoSelectTop, AutoSelectOff, *autoSelect)
+	}
+	config.AutoSelect = *autoSelect
+	if *dub {
+		if *mode == ModeSub {
+			return "", fmt.Errorf("dub can't be combined with -mode %s", ModeSub)
+		}
+		*mode = ModeDub
+	}
+	switch *mode {
+	case "", ModeSub, ModeDub:
+	default:
+		return "", fmt.Errorf("mode must be %s or %s, you entered: %s", ModeSub, ModeDub, *mode)
+	}
+	config.Mode = *mode
+	if *profile != "" && *profile != "cpu" && *profile != "mem" {
+		return "", fmt.Errorf("profile must be cpu or mem, you entered: %s", *profile)
+	}
+	config.Profile = *profile
+	if *mpvProfile != MPVProfileDefault && *mpvProfile != MPVProfileIsolated {
+		return "", fmt.Errorf("mpv-profile must be %s or %s, you entered: %s", MPVProfileDefault, MPVProfileIsolated, *mpvProfile)
+	}
+	config.MPVProfile = *mpvProfile
+	if *subScale <= 0 {
+		return "", fmt.Errorf("sub-scale must be greater than 0, you entered: %v", *subScale)
+	}
+	config.SubScale = *subScale
+	config.SubColor = *subColor
+	if *subPos < 0 || *subPos > 150 {
+		return "", fmt.Errorf("sub-pos must be between 0 and 150, you entered: %d", *subPos)
+	}
+	config.SubPos = *subPos
+	config.NoFallbackOpen = *noFallbackOpen
+	config.Dedupe = *dedupe
+	config.Verify = *verify
+	config.Thumbnails = *thumbnails
+	config.OutputTemplate = *outputTemplate
+	config.QualityInName = *qualityInName
+	config.PreferMP4 = *preferMP4
+	if *ytDlpPath != "" {
+		if err := CheckExecutable(*ytDlpPath); err != nil {
+			return "", fmt.Errorf("yt-dlp-path must be an executable file, you entered: %s (%w)", *ytDlpPath, err)
+		}
+	}
+	config.YtDlpPath = *ytDlpPath
+	config.ReportSource = *reportSource
+	config.SearchOnly = *searchOnly
+	config.EpisodesInfo = *episodesInfo
+	config.LogFile = *logFile
+	if *selectTimeout < 0 {
+		return "", fmt.Errorf("select-timeout must not be negative, you entered: %s", *selectTimeout)
+	}
+	config.SelectTimeout = *selectTimeout
+	if *connectTimeout <= 0 {
+		return "", fmt.Errorf("connect-timeout must be greater than 0, you entered: %s", *connectTimeout)
+	}
+	config.ConnectTimeout = *connectTimeout
+	if *headerTimeout <= 0 {
+		return "", fmt.Errorf("header-timeout must be greater than 0, you entered: %s", *headerTimeout)
+	}
+	config.HeaderTimeout = *headerTimeout
+	if *resolveTimeout <= 0 {
+		return "", fmt.Errorf("resolve-timeout must be greater than 0, you entered: %s", *resolveTimeout)
+	}
+	config.ResolveTimeout = *resolveTimeout
+	config.JSON = *jsonOutput
+	// "goanime <anime name> latest" plays the newest episode instead of asking for one
+	args := flag.Args()
+	if len(args) > 1 && strings.EqualFold(args[len(args)-1], "latest") {
+		config.Latest = true
+		args = args[:len(args)-1]
+	}
+	if *hostConcurrency != "" {
+		limits, err := ParseHostConcurrency(*hostConcurrency)
+		if err != nil {
+			return "", err
+		}
+		config.HostConcurrency = limits
+	}
+	for _, host := range strings.Split(*allowHosts, ",") {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			config.AllowedHosts = append(config.AllowedHosts, host)
+		}
+	}
+	SetConfig(config)
 	if *debug {
 		fmt.Println("--- Debug mode is enabled ---")
 	}
 	// If the user has provided an anime name as an argument, we use it.
 	var animeName string
-	if len(flag.Args()) > 0 {
-		animeName = strings.Join(flag.Args(), " ")
+	if len(args) > 0 {
+		animeName = strings.Join(args, " ")
 		// Check if it has some flags and remove them
 		if strings.Contains(animeName, "-") {
 			animeName = strings.Split(animeName, "-")[0]
 		}
-		fmt.Println("Anime name:", animeName)
+		// Keep stdout parseable for -json
+		if !*jsonOutput {
+			fmt.Println("Anime name:", animeName)
+		}
 		if len(animeName) < minNameLength {
 			return "", fmt.Errorf("anime name must have at least %d characters, you entered: %v", minNameLength, animeName)
 		}
 		return TreatingAnimeName(animeName), nil
 	}
+	// The anime of the interrupted batch download is saved with it
+	if *resumeBatch {
+		return "", nil
+	}
 	animeName, err := getUserInput("Enter anime name")
 	return TreatingAnimeName(animeName), err
 }
 
+// CheckExecutable returns an error unless path is a file the current user may run.
+// Windows has no executable bit, so there any existing file passes.
+func CheckExecutable(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o111 == 0 {
+		return fmt.Errorf("%s is not executable", path)
+	}
+	return nil
+}
+
+// ParseHostConcurrency parses a comma-separated list of host=N limits, as given to -host-concurrency.
+func ParseHostConcurrency(value string) (map[string]int, error) {
+	limits := make(map[string]int)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, limitStr, found := strings.Cut(entry, "=")
+		limit, err := strconv.Atoi(strings.TrimSpace(limitStr))
+		if !found || strings.TrimSpace(host) == "" || err != nil || limit < 1 {
+			return nil, fmt.Errorf("host-concurrency entries must look like host=N with N at least 1, you entered: %s", entry)
+		}
+		limits[strings.ToLower(strings.TrimSpace(host))] = limit
+	}
+	return limits, nil
+}
+
+// IsInteractive reports whether stdin is a terminal, i.e. whether there is someone to answer prompts.
+func IsInteractive() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
 // getUserInput prompts the user for input the anime name and returns it
 func getUserInput(label string) (string, error) {
 	prompt := promptui.Prompt{
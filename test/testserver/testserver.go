@@ -0,0 +1,101 @@
+// Package testserver serves canned AnimeFire responses so tests can drive the
+// search → episodes → video resolution pipeline without touching the network.
+package testserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// Host is the host the canned pages are served for. It matches the real site,
+// so the absolute URLs built by the api package resolve against the stub.
+const Host = "animefire.plus"
+
+// AnimeURL is the anime page served by the stub.
+const AnimeURL = "https://" + Host + "/animes/stub-anime-todos-os-episodios"
+
+// EpisodeCount is the number of episodes listed on AnimeURL.
+const EpisodeCount = 3
+
+// EpisodeURL returns the page URL of the given episode.
+func EpisodeURL(n int) string {
+	return fmt.Sprintf("https://%s/animes/stub-anime/%d", Host, n)
+}
+
+// VideoAPIURL returns the video JSON endpoint referenced by the given episode page.
+func VideoAPIURL(n int) string {
+	return fmt.Sprintf("https://%s/video/stub-anime/%d", Host, n)
+}
+
+// StreamURL returns the stream URL the stub resolves the given episode and quality to.
+func StreamURL(n int, quality string) string {
+	return fmt.Sprintf("https://cdn.%s/stub-anime/%d/%s.mp4", Host, n, quality)
+}
+
+// Server is a stub of the AnimeFire site.
+type Server struct {
+	mux      *http.ServeMux
+	Requests []string // Paths requested so far, in order
+}
+
+// New returns a Server with the search, anime, episode and video endpoints registered.
+func New() *Server {
+	s := &Server{mux: http.NewServeMux()}
+
+	s.mux.HandleFunc("/pesquisar/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<html><body><div class="row ml-1 mr-1">
+			<a href="/animes/stub-anime-todos-os-episodios">Stub Anime</a>
+			<a href="/animes/stub-anime-dublado-todos-os-episodios">Stub Anime (Dublado)</a>
+		</div></body></html>`)
+	})
+
+	s.mux.HandleFunc("/animes/stub-anime-todos-os-episodios", func(w http.ResponseWriter, r *http.Request) {
+		var links strings.Builder
+		for n := EpisodeCount; n >= 1; n-- {
+			fmt.Fprintf(&links, `<a class="lEp epT divNumEp smallbox px-2 mx-1 text-left d-flex" href="%s">Episódio %d</a>`, EpisodeURL(n), n)
+		}
+		fmt.Fprintf(w, `<html><head><meta property="og:image" content="https://%s/img/stub-anime.webp"></head><body>%s</body></html>`, Host, links.String())
+	})
+
+	s.mux.HandleFunc("/animes/stub-anime/", func(w http.ResponseWriter, r *http.Request) {
+		n := strings.TrimPrefix(r.URL.Path, "/animes/stub-anime/")
+		fmt.Fprintf(w, `<html><body><video id="my-video" data-video-src="https://%s/video/stub-anime/%s"></video></body></html>`, Host, n)
+	})
+
+	s.mux.HandleFunc("/video/stub-anime/", func(w http.ResponseWriter, r *http.Request) {
+		var n int
+		if _, err := fmt.Sscanf(strings.TrimPrefix(r.URL.Path, "/video/stub-anime/"), "%d", &n); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data":[{"src":"%s","label":"360p"},{"src":"%s","label":"720p"}]}`, StreamURL(n, "360p"), StreamURL(n, "720p"))
+	})
+
+	return s
+}
+
+// Handle registers an extra handler, or overrides a canned one, on the stub.
+func (s *Server) Handle(pattern string, handler http.HandlerFunc) {
+	s.mux.HandleFunc(pattern, handler)
+}
+
+// Do serves the request in-process, which lets the stub be injected with api.SetHTTPDoer.
+// Requests for any other host get a 502 so a test never silently reaches the real site.
+func (s *Server) Do(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != Host {
+		rec := httptest.NewRecorder()
+		http.Error(rec, "unexpected host "+req.URL.Host, http.StatusBadGateway)
+		return rec.Result(), nil
+	}
+
+	s.Requests = append(s.Requests, req.URL.Path)
+
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, req)
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
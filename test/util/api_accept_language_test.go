@@ -0,0 +1,31 @@
+package test_util_test
+
+import (
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfiguredAcceptLanguageIsSent(t *testing.T) {
+	doer := &fakeDoer{bodies: map[string]string{
+		fixtureAnimeURL: readFixture(t, "animefire_episodes.html"),
+	}}
+	defer api.SetHTTPDoer(doer)()
+
+	config := util.DefaultConfig()
+	config.AcceptLanguage = "es-ES,es;q=0.9"
+	defer util.SetConfig(config)()
+	_, err := api.GetAnimeEpisodes(fixtureAnimeURL)
+	assert.NoError(t, err)
+
+	util.SetConfig(util.DefaultConfig())
+	_, err = api.GetAnimeEpisodes(fixtureAnimeURL)
+	assert.NoError(t, err)
+
+	if assert.Len(t, doer.requests, 2) {
+		assert.Equal(t, "es-ES,es;q=0.9", doer.requests[0].Header.Get("Accept-Language"))
+		assert.Empty(t, doer.requests[1].Header.Get("Accept-Language"))
+	}
+}
@@ -0,0 +1,32 @@
+package test_util_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEpisodesAiredSince(t *testing.T) {
+	episodes := []api.Episode{
+		{Number: "Episódio 1", Num: 1, Aired: "2024-12-29T00:00:00+00:00"},
+		{Number: "Episódio 2", Num: 2, Aired: "2025-01-01T00:00:00+00:00"},
+		{Number: "Episódio 3", Num: 3},
+		{Number: "Episódio 4", Num: 4, Aired: "2025-01-12"},
+		{Number: "Episódio 5", Num: 5, Aired: "not a date"},
+	}
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	nums := func(episodes []api.Episode) []int {
+		var nums []int
+		for _, episode := range episodes {
+			nums = append(nums, episode.Num)
+		}
+		return nums
+	}
+
+	assert.Equal(t, []int{2, 4}, nums(api.EpisodesAiredSince(episodes, since, false)), "the day itself counts and undated episodes are dropped")
+	assert.Equal(t, []int{2, 3, 4, 5}, nums(api.EpisodesAiredSince(episodes, since, true)))
+	assert.Empty(t, api.EpisodesAiredSince(episodes, since.AddDate(1, 0, 0), false))
+}
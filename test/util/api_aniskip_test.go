@@ -0,0 +1,44 @@
+package test_util_test
+
+import (
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleAniSkipResponse = `{
+	"found": true,
+	"results": [
+		{"interval": {"start_time": 88.5, "end_time": 178.49}, "skip_type": "op", "skip_id": "a", "episode_length": 1420.1},
+		{"interval": {"start_time": 1300.2, "end_time": 1390}, "skip_type": "ed", "skip_id": "b", "episode_length": 1420.1}
+	]
+}`
+
+func TestParseAniSkipResponseIntoIntervals(t *testing.T) {
+	var episode api.Episode
+
+	err := api.ParseAniSkipResponse(sampleAniSkipResponse, &episode, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, api.Skip{Start: 89, End: 178}, episode.SkipTimes.Op)
+	assert.Equal(t, api.Skip{Start: 1300, End: 1390}, episode.SkipTimes.Ed)
+}
+
+func TestAniSkipDataIsCachedPerEpisode(t *testing.T) {
+	doer := &fakeDoer{bodies: map[string]string{
+		"https://api.aniskip.com/v1/skip-times/991/7?types=op&types=ed": sampleAniSkipResponse,
+	}}
+	defer api.SetHTTPDoer(doer)()
+
+	for i := 0; i < 2; i++ {
+		var episode api.Episode
+		assert.NoError(t, api.GetAndParseAniSkipData(991, 7, &episode))
+		assert.Equal(t, 89, episode.SkipTimes.Op.Start)
+	}
+	assert.Len(t, doer.requests, 1, "the second lookup is served from the cache")
+
+	_, err := api.GetAniSkipData(0, 7)
+	assert.Error(t, err, "anime without a MAL ID")
+	assert.Len(t, doer.requests, 1)
+}
@@ -0,0 +1,41 @@
+package test_util_test
+
+import (
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchConfidence(t *testing.T) {
+	assert.Equal(t, 1.0, api.MatchConfidence("one-piece", "One Piece"))
+	assert.Equal(t, 0.0, api.MatchConfidence("one-piece", "Naruto"))
+	assert.Less(t, api.MatchConfidence("one-piece", "Piece One"), 1.0)
+	assert.Greater(t,
+		api.MatchConfidence("one-piece", "One Piece (Dublado)"),
+		api.MatchConfidence("one-piece", "One Piece Film: Red"))
+}
+
+func TestAutoSelectExactMatch(t *testing.T) {
+	animes := []api.Anime{{Name: "One Piece Film: Red"}, {Name: "One Piece"}, {Name: "One Piece (Dublado)"}}
+
+	anime, ok := api.AutoSelectAnime("one-piece", animes, util.AutoSelectExact)
+
+	assert.True(t, ok)
+	assert.Equal(t, "One Piece", anime.Name)
+}
+
+func TestAutoSelectAmbiguousResults(t *testing.T) {
+	animes := []api.Anime{{Name: "Boruto: Naruto Next Generations"}, {Name: "Naruto Shippuden"}, {Name: "Naruto (Dublado)"}}
+
+	_, ok := api.AutoSelectAnime("naruto", animes, util.AutoSelectExact)
+	assert.False(t, ok, "no result is named exactly like the query, so the user chooses")
+
+	anime, ok := api.AutoSelectAnime("naruto", animes, util.AutoSelectTop)
+	assert.True(t, ok)
+	assert.Equal(t, "Naruto Shippuden", anime.Name, "the first of the best matches is taken")
+
+	_, ok = api.AutoSelectAnime("one-piece", []api.Anime{{Name: "One Piece"}}, util.AutoSelectOff)
+	assert.False(t, ok)
+}
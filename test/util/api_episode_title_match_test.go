@@ -0,0 +1,66 @@
+package test_util_test
+
+import (
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/stretchr/testify/assert"
+)
+
+var titledEpisodes = []api.Episode{
+	{Num: 1, Number: "Episódio 1", Title: api.TitleDetails{English: "Enter: Naruto Uzumaki!"}},
+	{Num: 2, Number: "Episódio 2", Title: api.TitleDetails{English: "My Name is Konohamaru!"}},
+	{Num: 3, Number: "Episódio 3", Title: api.TitleDetails{English: "Sasuke and Sakura: Friends or Foes?"}},
+	{Num: 219, Number: "Episódio 219", Title: api.TitleDetails{English: "The Finale", Romaji: "Saishuukai"}},
+	{Num: 220, Number: "Episódio 220", Title: api.TitleDetails{English: "Departure: The Finale Begins"}},
+	{Num: 4, Number: "Episódio 4"},
+}
+
+func TestFindEpisodeByTitleSubstring(t *testing.T) {
+	index, err := api.FindEpisodeByTitle(titledEpisodes, "konohamaru")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2, titledEpisodes[index].Num)
+	}
+
+	index, err = api.FindEpisodeByTitle(titledEpisodes, "sakura sasuke")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 3, titledEpisodes[index].Num)
+	}
+
+	index, err = api.FindEpisodeByTitle(titledEpisodes, "saishuukai")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 219, titledEpisodes[index].Num)
+	}
+}
+
+func TestFindEpisodeByTitlePrefersExactMatch(t *testing.T) {
+	index, err := api.FindEpisodeByTitle(titledEpisodes, "the finale")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 219, titledEpisodes[index].Num)
+	}
+}
+
+func TestFindEpisodeByTitleAmbiguousListsMatches(t *testing.T) {
+	_, err := api.FindEpisodeByTitle(titledEpisodes, "finale")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "219: The Finale")
+		assert.Contains(t, err.Error(), "220: Departure: The Finale Begins")
+	}
+}
+
+func TestFindEpisodeByTitleNoMatch(t *testing.T) {
+	index, err := api.FindEpisodeByTitle(titledEpisodes, "chunin exams")
+	assert.Error(t, err)
+	assert.Equal(t, -1, index)
+}
+
+func TestFindEpisodeByTitleReturnsTheIndexOfEntriesSharingANumber(t *testing.T) {
+	episodes := []api.Episode{
+		{Num: 1, Number: "Episódio 1", Title: api.TitleDetails{English: "Enter: Naruto Uzumaki!"}},
+		{Num: 1, Number: "Episódio 1 - Especial", Title: api.TitleDetails{English: "Special: Konoha Sports Festival"}},
+	}
+
+	index, err := api.FindEpisodeByTitle(episodes, "sports festival")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, index)
+}
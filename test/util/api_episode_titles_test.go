@@ -0,0 +1,46 @@
+package test_util_test
+
+import (
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/stretchr/testify/assert"
+)
+
+const jikanEpisodesPage = `{
+	"pagination": {"last_visible_page": 1, "has_next_page": false},
+	"data": [
+		{"mal_id": 1, "title": "Enter: Naruto Uzumaki!", "title_japanese": "参上!うずまきナルト", "title_romanji": "Sanjou! Uzumaki Naruto", "aired": "2002-10-03T00:00:00+00:00", "filler": false, "recap": false},
+		{"mal_id": 3, "title": "Sasuke and Sakura: Friends or Foes?", "title_japanese": "宿敵!?サスケとサクラ", "title_romanji": "Shukuteki!? Sasuke to Sakura", "aired": "2002-10-17T00:00:00+00:00", "filler": false, "recap": true}
+	]
+}`
+
+func TestFetchAndMergeEpisodeTitles(t *testing.T) {
+	doer := &fakeDoer{bodies: map[string]string{
+		"https://api.jikan.moe/v4/anime/20/episodes?page=1": jikanEpisodesPage,
+	}}
+	defer api.SetHTTPDoer(doer)()
+
+	titles, err := api.FetchEpisodeTitles(20)
+	assert.NoError(t, err)
+	assert.Len(t, titles, 2)
+
+	// A second call is served from the per-anime cache
+	_, err = api.FetchEpisodeTitles(20)
+	assert.NoError(t, err)
+	assert.Len(t, doer.requests, 1)
+
+	episodes := []api.Episode{
+		{Number: "Episódio 1", Num: 1},
+		{Number: "Episódio 2", Num: 2},
+		{Number: "Episódio 3", Num: 3},
+	}
+	api.MergeEpisodeTitles(episodes, titles)
+
+	assert.Equal(t, "Enter: Naruto Uzumaki!", episodes[0].Title.English)
+	assert.Equal(t, "Sanjou! Uzumaki Naruto", episodes[0].Title.Romaji)
+	assert.Equal(t, "2002-10-03T00:00:00+00:00", episodes[0].Aired)
+	assert.Empty(t, episodes[1].Title.English)
+	assert.Equal(t, "Sasuke and Sakura: Friends or Foes?", episodes[2].Title.English)
+	assert.True(t, episodes[2].IsRecap)
+}
@@ -0,0 +1,45 @@
+package test_util_test
+
+import (
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEpisodeNumberFromURLSchemes(t *testing.T) {
+	for _, episodeURL := range []string{
+		"https://animefire.plus/animes/naruto/1",
+		"https://animefire.plus/animes/naruto/1/",
+		"https://example.com/naruto-episodio-01",
+		"https://example.com/naruto-episodio-1",
+		"https://example.com/naruto_1",
+		"https://example.com/naruto/episode1",
+		"https://example.com/naruto/episode-001.html?server=2",
+	} {
+		num, ok := api.EpisodeNumberFromURL(episodeURL)
+		assert.True(t, ok, episodeURL)
+		assert.Equal(t, 1, num, episodeURL)
+	}
+}
+
+func TestEpisodeNumberFromURLWithoutNumber(t *testing.T) {
+	_, ok := api.EpisodeNumberFromURL("https://example.com/naruto/episodio-final")
+	assert.False(t, ok)
+}
+
+func TestGetAnimeEpisodesNumbersUnnumberedLabelsFromURL(t *testing.T) {
+	page := `<html><body>
+<a class="lEp epT divNumEp smallbox px-2 mx-1 text-left d-flex" href="https://animefire.plus/animes/naruto/1">Episódio 1</a>
+<a class="lEp epT divNumEp smallbox px-2 mx-1 text-left d-flex" href="https://animefire.plus/animes/naruto/02">Episódio Final</a>
+</body></html>`
+	doer := &fakeDoer{bodies: map[string]string{"https://animefire.plus/animes/naruto-todos-os-episodios": page}}
+	defer api.SetHTTPDoer(doer)()
+
+	episodes, err := api.GetAnimeEpisodes("https://animefire.plus/animes/naruto-todos-os-episodios")
+	assert.NoError(t, err)
+	if assert.Len(t, episodes, 2) {
+		assert.Equal(t, 1, episodes[0].Num)
+		assert.Equal(t, 2, episodes[1].Num)
+	}
+}
@@ -0,0 +1,30 @@
+package test_util_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/stretchr/testify/assert"
+)
+
+// sampleEpisodesInfo is a listing with some titles, durations and filler/recap flags merged in.
+func sampleEpisodesInfo() []api.Episode {
+	return []api.Episode{
+		{Number: "Episódio 1", Num: 1, URL: "https://animefire.plus/animes/naruto/1", Title: api.TitleDetails{English: "Enter: Naruto Uzumaki!", Romaji: "Sanjou! Uzumaki Naruto"}, Aired: "2002-10-03", Duration: 1380},
+		{Number: "Episódio 2", Num: 2, URL: "https://animefire.plus/animes/naruto/2", Title: api.TitleDetails{Romaji: "Konohamaru"}, IsFiller: true},
+		{Number: "Episódio 3", Num: 3, URL: "https://animefire.plus/animes/naruto/3", IsRecap: true},
+	}
+}
+
+func TestWriteEpisodesInfoTable(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, api.WriteEpisodesInfo(&out, sampleEpisodesInfo(), false))
+	assert.Equal(t, readFixture(t, "episodes_info.golden"), out.String())
+}
+
+func TestWriteEpisodesInfoJSON(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, api.WriteEpisodesInfo(&out, sampleEpisodesInfo(), true))
+	assert.JSONEq(t, readFixture(t, "episodes_info.golden.json"), out.String())
+}
@@ -0,0 +1,47 @@
+package test_util_test
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeDoer answers requests with canned bodies keyed by the full request URL.
+// Unknown URLs get a 404 so tests fail loudly instead of reaching the network.
+type fakeDoer struct {
+	bodies   map[string]string
+	requests []*http.Request
+}
+
+func (f *fakeDoer) Do(req *http.Request) (*http.Response, error) {
+	f.requests = append(f.requests, req)
+
+	body, ok := f.bodies[req.URL.String()]
+	status := http.StatusOK
+	if !ok {
+		status = http.StatusNotFound
+		body = "not found"
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// readFixture loads a file from the testdata directory.
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Failed to read fixture %s: %v", name, err)
+	}
+	return string(data)
+}
@@ -0,0 +1,71 @@
+package test_util_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/stretchr/testify/assert"
+)
+
+const fixtureAnimeURL = "https://animefire.plus/animes/naruto-todos-os-episodios"
+
+func TestParseAnimesFromSearchFixture(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(readFixture(t, "animefire_search.html")))
+	if err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+
+	animes := api.ParseAnimes(doc)
+
+	assert.Equal(t, []api.Anime{
+		{Name: "Naruto", URL: "https://animefire.plus/animes/naruto-todos-os-episodios"},
+		{Name: "Naruto (Dublado)", URL: "https://animefire.plus/animes/naruto-dublado-todos-os-episodios"},
+		{Name: "Boruto: Naruto Next Generations", URL: "https://animefire.plus/animes/boruto-naruto-next-generations-todos-os-episodios"},
+	}, animes)
+}
+
+func TestGetAnimeEpisodesWithInjectedDoer(t *testing.T) {
+	doer := &fakeDoer{bodies: map[string]string{
+		fixtureAnimeURL: readFixture(t, "animefire_episodes.html"),
+	}}
+	defer api.SetHTTPDoer(doer)()
+
+	episodes, err := api.GetAnimeEpisodes(fixtureAnimeURL)
+
+	assert.NoError(t, err)
+	assert.Len(t, doer.requests, 1)
+	if assert.Len(t, episodes, 3) {
+		for i, episode := range episodes {
+			assert.Equal(t, i+1, episode.Num)
+		}
+		assert.Equal(t, "https://animefire.plus/animes/naruto/1", episodes[0].URL)
+	}
+}
+
+func TestFetchAnimeDetailsWithInjectedDoer(t *testing.T) {
+	doer := &fakeDoer{bodies: map[string]string{
+		fixtureAnimeURL: readFixture(t, "animefire_episodes.html"),
+	}}
+	defer api.SetHTTPDoer(doer)()
+
+	err := api.FetchAnimeDetails(&api.Anime{Name: "Naruto", URL: fixtureAnimeURL})
+	assert.NoError(t, err)
+
+	err = api.FetchAnimeDetails(&api.Anime{Name: "Missing", URL: "https://animefire.plus/animes/missing"})
+	assert.Error(t, err)
+}
+
+func TestGetAnimeEpisodesReturnsErrNoEpisodesForEmptyList(t *testing.T) {
+	doer := &fakeDoer{bodies: map[string]string{
+		fixtureAnimeURL: "<html><body><div class=\"div_video_list\"></div></body></html>",
+	}}
+	defer api.SetHTTPDoer(doer)()
+
+	episodes, err := api.GetAnimeEpisodes(fixtureAnimeURL)
+
+	assert.Empty(t, episodes)
+	assert.True(t, errors.Is(err, api.ErrNoEpisodes))
+}
@@ -0,0 +1,58 @@
+package test_util_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/stretchr/testify/assert"
+)
+
+// statusDoer answers every request with the same status and body.
+type statusDoer struct {
+	status int
+	body   string
+}
+
+func (d statusDoer) Do(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: d.status,
+		Status:     http.StatusText(d.status),
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(d.body)),
+		Request:    req,
+	}, nil
+}
+
+func TestIsGeoBlocked(t *testing.T) {
+	assert.True(t, api.IsGeoBlocked(http.StatusForbidden, []byte("<h1>Sorry, this video is not available in your country.</h1>")))
+	assert.True(t, api.IsGeoBlocked(http.StatusForbidden, []byte("Este conteúdo não está disponível no seu país")))
+	assert.True(t, api.IsGeoBlocked(http.StatusUnavailableForLegalReasons, nil))
+	assert.False(t, api.IsGeoBlocked(http.StatusForbidden, []byte("<title>Just a moment...</title>")))
+	assert.False(t, api.IsGeoBlocked(http.StatusOK, []byte("not available in your region")))
+}
+
+func TestSafeGetClassifiesGeoBlock(t *testing.T) {
+	defer api.SetHTTPDoer(statusDoer{status: http.StatusForbidden, body: "This content is not available in your region."})()
+
+	resp, err := api.SafeGet(fixtureAnimeURL)
+
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, api.ErrGeoBlocked))
+	assert.Contains(t, err.Error(), "animefire.plus")
+}
+
+func TestSafeGetKeepsOtherForbiddenBodies(t *testing.T) {
+	defer api.SetHTTPDoer(statusDoer{status: http.StatusForbidden, body: "Access denied"})()
+
+	resp, err := api.SafeGet(fixtureAnimeURL)
+
+	if assert.NoError(t, err) {
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "Access denied", string(body))
+		assert.NoError(t, resp.Body.Close())
+	}
+}
@@ -0,0 +1,97 @@
+package test_util_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// roundTripFunc turns a function into an http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// closeFunc is a response body calling onClose when closed.
+type closeFunc struct {
+	io.Reader
+	onClose func()
+}
+
+func (c closeFunc) Close() error {
+	c.onClose()
+	return nil
+}
+
+// maxConcurrent sends n requests to rawURL through the limiter's transport and returns the highest number of
+// requests seen open at once by the server side, from the request until its body is closed.
+func maxConcurrent(limiter *api.HostLimiter, rawURL string, n int) int32 {
+	var running, peak int32
+	client := &http.Client{Transport: limiter.Transport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		now := atomic.AddInt32(&running, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if now <= old || atomic.CompareAndSwapInt32(&peak, old, now) {
+				break
+			}
+		}
+		body := closeFunc{Reader: strings.NewReader("part"), onClose: func() { atomic.AddInt32(&running, -1) }}
+		return &http.Response{StatusCode: http.StatusOK, Body: body, Request: req}, nil
+	}))}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(rawURL)
+			if err != nil {
+				return
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			time.Sleep(20 * time.Millisecond)
+			_ = resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+	return peak
+}
+
+func TestHostLimiterCapsConcurrentRequestsPerHost(t *testing.T) {
+	limiter := api.NewHostLimiter(map[string]int{"animefire.plus": 2}, 5)
+
+	assert.Equal(t, int32(2), maxConcurrent(limiter, "https://animefire.plus/video/naruto/1", 8))
+	assert.Equal(t, int32(2), maxConcurrent(limiter, "https://cdn.animefire.plus/video.mp4", 8))
+	assert.Equal(t, int32(5), maxConcurrent(limiter, "https://other.example/video.mp4", 8))
+}
+
+func TestHostLimiterReleasesFailedRequests(t *testing.T) {
+	limiter := api.NewHostLimiter(map[string]int{"animefire.plus": 1}, 5)
+	client := &http.Client{Transport: limiter.Transport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	}))}
+
+	for i := 0; i < 3; i++ {
+		_, err := client.Get("https://animefire.plus/video/naruto/1")
+		assert.Error(t, err, "a failed request must not keep the only slot")
+	}
+}
+
+func TestParseHostConcurrency(t *testing.T) {
+	limits, err := util.ParseHostConcurrency("AnimeFire.plus=1, example.com=3")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"animefire.plus": 1, "example.com": 3}, limits)
+
+	_, err = util.ParseHostConcurrency("animefire.plus=0")
+	assert.Error(t, err)
+	_, err = util.ParseHostConcurrency("animefire.plus")
+	assert.Error(t, err)
+}
@@ -0,0 +1,43 @@
+package test_util_test
+
+import (
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSeriesFromEpisodesSingleEpisodeMovie(t *testing.T) {
+	episodes := []api.Episode{{Number: "Filme", Num: 1, URL: "https://animefire.plus/filmes/kimi-no-na-wa/1"}}
+
+	assert.False(t, api.IsSeriesFromEpisodes("https://animefire.plus/filmes/kimi-no-na-wa", episodes))
+	assert.False(t, api.IsSeriesFromEpisodes("https://animefire.plus/animes/one-piece-filme-red-todos-os-episodios", episodes))
+}
+
+func TestIsSeriesFromEpisodesSingleEpisodeSeries(t *testing.T) {
+	episodes := []api.Episode{{Number: "Episódio 1", Num: 1, URL: "https://animefire.plus/animes/new-show/1"}}
+
+	assert.True(t, api.IsSeriesFromEpisodes("https://animefire.plus/animes/new-show-todos-os-episodios", episodes))
+	assert.False(t, api.IsSeriesFromEpisodes("https://animefire.plus/animes/some-ova", episodes))
+}
+
+func TestIsSeriesFromEpisodesMultipleEpisodes(t *testing.T) {
+	episodes := []api.Episode{{Num: 1}, {Num: 2}, {Num: 3}}
+
+	assert.True(t, api.IsSeriesFromEpisodes("https://animefire.plus/animes/naruto-todos-os-episodios", episodes))
+	assert.True(t, api.IsSeriesFromEpisodes("https://animefire.plus/filmes/movie-trilogy", episodes))
+	assert.False(t, api.IsSeriesFromEpisodes(fixtureAnimeURL, nil))
+}
+
+func TestIsSeriesWithInjectedDoer(t *testing.T) {
+	doer := &fakeDoer{bodies: map[string]string{
+		fixtureAnimeURL: readFixture(t, "animefire_episodes.html"),
+	}}
+	defer api.SetHTTPDoer(doer)()
+
+	series, total, err := api.IsSeries(fixtureAnimeURL)
+
+	assert.NoError(t, err)
+	assert.True(t, series)
+	assert.Equal(t, 3, total)
+}
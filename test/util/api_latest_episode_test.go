@@ -0,0 +1,30 @@
+package test_util_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatestEpisodeUsesHighestNumber(t *testing.T) {
+	episodes := []api.Episode{
+		{Number: "Episódio 12", Num: 12},
+		{Number: "Episódio 2", Num: 2},
+		{Number: "Episódio 13", Num: 13},
+		{Number: "Episódio 13", Num: 13, URL: "reupload"},
+		{Number: "OVA", Num: 0},
+	}
+
+	latest, err := api.LatestEpisode(episodes)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, latest, "the first of equally numbered episodes wins")
+}
+
+func TestLatestEpisodeWithoutEpisodes(t *testing.T) {
+	_, err := api.LatestEpisode(nil)
+
+	assert.True(t, errors.Is(err, api.ErrNoEpisodes))
+}
@@ -0,0 +1,52 @@
+package test_util_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/alvarorichard/Goanime/test/testserver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterByMode(t *testing.T) {
+	animes := []api.Anime{
+		{Name: "Naruto", URL: "https://animefire.plus/animes/naruto-todos-os-episodios"},
+		{Name: "Naruto (Dublado)", URL: "https://animefire.plus/animes/naruto-dublado-todos-os-episodios"},
+		{Name: "Naruto Shippuden", URL: "https://animefire.plus/animes/naruto-shippuden-todos-os-episodios"},
+	}
+
+	assert.Equal(t, animes, api.FilterByMode(animes, ""))
+	assert.Equal(t, []api.Anime{animes[1]}, api.FilterByMode(animes, util.ModeDub))
+	assert.Equal(t, []api.Anime{animes[0], animes[2]}, api.FilterByMode(animes, util.ModeSub))
+}
+
+func TestFilterByModeFallsBackWhenNoDubExists(t *testing.T) {
+	animes := []api.Anime{
+		{Name: "Frieren", URL: "https://animefire.plus/animes/sousou-no-frieren-todos-os-episodios"},
+	}
+
+	logs := captureLog(t)
+	assert.Equal(t, animes, api.FilterByMode(animes, util.ModeDub))
+	assert.Contains(t, logs.String(), "No dubbed version found")
+}
+
+func TestSearchOnlyListsDubsWithModeDub(t *testing.T) {
+	server := testserver.New()
+	defer api.SetHTTPDoer(server)()
+
+	config := util.DefaultConfig()
+	config.Mode = util.ModeDub
+	defer util.SetConfig(config)()
+
+	var out bytes.Buffer
+	assert.NoError(t, api.SearchOnly(&out, "stub anime", true))
+
+	var results []api.SearchResult
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &results))
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "Stub Anime (Dublado)", results[0].Name)
+	}
+}
@@ -0,0 +1,26 @@
+package test_util_test
+
+import (
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTextRepairsMojibake(t *testing.T) {
+	// UTF-8 read as Latin-1 and as Windows-1252
+	assert.Equal(t, "Até o Fim do Mundo", api.NormalizeText("AtÃ© o Fim do Mundo"))
+	assert.Equal(t, "進撃の巨人", api.NormalizeText("é€²æ’ƒã\u0081®å·¨äºº"))
+	// Raw Latin-1 bytes
+	assert.Equal(t, "Coração", api.NormalizeText("Cora\xe7\xe3o"))
+}
+
+func TestNormalizeTextKeepsCorrectTitles(t *testing.T) {
+	for _, title := range []string{"Shingeki no Kyojin", "Até o Fim", "進撃の巨人", "Ãmbar"} {
+		assert.Equal(t, title, api.NormalizeText(title))
+	}
+}
+
+func TestNormalizeTextStripsControlCharacters(t *testing.T) {
+	assert.Equal(t, "Episódio 12", api.NormalizeText("\n  Episódio\t\x00\u200b12\r\n"))
+}
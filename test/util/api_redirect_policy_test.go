@@ -0,0 +1,41 @@
+package test_util_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedirectPolicyLimitsHops(t *testing.T) {
+	// /hops/N redirects to /hops/N-1 until /hops/0 answers 200
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/hops/"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		if n == 0 {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		http.Redirect(w, r, fmt.Sprintf("/hops/%d", n-1), http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := &http.Client{CheckRedirect: api.RedirectPolicy(3)}
+
+	resp, err := client.Get(server.URL + "/hops/3")
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+	}
+
+	_, err = client.Get(server.URL + "/hops/4")
+	assert.ErrorContains(t, err, "stopped after 3 redirects")
+}
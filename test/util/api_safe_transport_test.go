@@ -0,0 +1,128 @@
+package test_util_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafeTransportBlocksLoopbackAndLinkLocal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: api.SafeTransport(2 * time.Second)}
+
+	_, err := client.Get(server.URL)
+	assert.Error(t, err, "loopback must be blocked")
+
+	_, err = client.Get("http://169.254.169.254/latest/meta-data/")
+	assert.ErrorContains(t, err, "ip address is not allowed")
+
+	config := util.DefaultConfig()
+	config.AllowPrivateIPs = true
+	defer util.SetConfig(config)()
+
+	resp, err := client.Get(server.URL)
+	if assert.NoError(t, err, "opt-out must allow local test servers") {
+		_ = resp.Body.Close()
+	}
+}
+
+func TestSafeTransportEnforcesAllowedHosts(t *testing.T) {
+	config := util.DefaultConfig()
+	config.AllowedHosts = []string{"animefire.plus"}
+	defer util.SetConfig(config)()
+
+	assert.True(t, api.IsAllowedHost("animefire.plus"))
+	assert.True(t, api.IsAllowedHost("cdn.animefire.plus"))
+	assert.False(t, api.IsAllowedHost("evil-animefire.plus"))
+	assert.False(t, api.IsAllowedHost("169.254.169.254"))
+
+	client := &http.Client{Transport: api.SafeTransport(2 * time.Second)}
+	_, err := client.Get("http://example.com/")
+	assert.ErrorContains(t, err, "not in the allowed hosts list")
+}
+
+func TestSafeTransportHeaderTimeoutSparesSlowBodies(t *testing.T) {
+	config := util.DefaultConfig()
+	config.AllowPrivateIPs = true
+	defer util.SetConfig(config)()
+
+	slowHeaders := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slowHeaders.Close()
+
+	slowBody := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		for i := 0; i < 3; i++ {
+			_, _ = w.Write([]byte("chunk"))
+			w.(http.Flusher).Flush()
+			time.Sleep(150 * time.Millisecond)
+		}
+	}))
+	defer slowBody.Close()
+
+	client := &http.Client{Transport: api.SafeTransportTimeouts(2*time.Second, 100*time.Millisecond)}
+
+	_, err := client.Get(slowHeaders.URL)
+	assert.ErrorContains(t, err, "timeout awaiting response headers")
+
+	resp, err := client.Get(slowBody.URL)
+	if assert.NoError(t, err) {
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, "chunkchunkchunk", string(body))
+	}
+}
+
+func TestSafeHeadUsesConfiguredTimeouts(t *testing.T) {
+	config := util.DefaultConfig()
+	config.AllowPrivateIPs = true
+	defer util.SetConfig(config)()
+
+	slowHeaders := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slowHeaders.Close()
+
+	config.HeaderTimeout = 100 * time.Millisecond
+	defer util.SetConfig(config)()
+
+	_, err := api.SafeHead(slowHeaders.URL)
+	assert.ErrorContains(t, err, "timeout awaiting response headers")
+}
+
+func TestSafeHeadWaitsAsLongAsTheHeaderTimeout(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits more than 10 seconds for the answer")
+	}
+	config := util.DefaultConfig()
+	config.AllowPrivateIPs = true
+	config.HeaderTimeout = 15 * time.Second
+	defer util.SetConfig(config)()
+
+	// Slower than the 10 second limit HEAD probes used to have, but within -header-timeout
+	slowHeaders := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10500 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slowHeaders.Close()
+
+	resp, err := api.SafeHead(slowHeaders.URL)
+	if assert.NoError(t, err) {
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+}
@@ -0,0 +1,58 @@
+package test_util_test
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// pickFirstAnime replaces the interactive fuzzy finder in tests.
+func pickFirstAnime(animes []api.Anime) (*api.Anime, error) {
+	return &animes[0], nil
+}
+
+func TestSimplifyQuery(t *testing.T) {
+	assert.Equal(t, "attack-on-titan", api.SimplifyQuery("attack-on-titan:-season-2"))
+	assert.Equal(t, "mushoku-tensei", api.SimplifyQuery("mushoku-tensei-2nd-season-part-1"))
+	assert.Equal(t, "overlord-iii", api.SimplifyQuery("overlord-iii")) // a bare numeral is part of the title
+	assert.Equal(t, "overlord", api.SimplifyQuery("overlord-season-iii"))
+	assert.Equal(t, "dr-stone", api.SimplifyQuery("dr.-stone-temporada-3"))
+}
+
+func TestSearchAnimeRetriesWithSimplifiedQuery(t *testing.T) {
+	doer := &fakeDoer{bodies: map[string]string{
+		"https://animefire.plus/pesquisar/naruto:-season-2": `<html><body><div class="row ml-1 mr-1"></div></body></html>`,
+		"https://animefire.plus/pesquisar/naruto":           readFixture(t, "animefire_search.html"),
+	}}
+	defer api.SetHTTPDoer(doer)()
+	defer api.SetAnimeSelector(pickFirstAnime)()
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	anime, err := api.SearchAnime("naruto:-season-2")
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, anime) {
+		assert.Equal(t, "Naruto", anime.Name)
+	}
+	assert.Contains(t, logs.String(), `retrying with "naruto"`)
+}
+
+func TestSearchAnimeWithoutResultsSuggestsAlternatives(t *testing.T) {
+	doer := &fakeDoer{bodies: map[string]string{
+		"https://animefire.plus/pesquisar/narutoo": `<html><body></body></html>`,
+	}}
+	defer api.SetHTTPDoer(doer)()
+
+	_, err := api.SearchAnime("narutoo")
+
+	assert.True(t, errors.Is(err, api.ErrNoAnimeFound))
+	assert.Contains(t, err.Error(), "check the spelling")
+}
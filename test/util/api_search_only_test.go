@@ -0,0 +1,47 @@
+package test_util_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchOnlyPrintsResultsWithoutListingEpisodes(t *testing.T) {
+	doer := &fakeDoer{bodies: map[string]string{
+		"https://animefire.plus/pesquisar/naruto": readFixture(t, "animefire_search.html"),
+	}}
+	defer api.SetHTTPDoer(doer)()
+
+	var out bytes.Buffer
+	err := api.SearchOnly(&out, "naruto", false)
+
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "NAME")
+	assert.Contains(t, out.String(), "Naruto")
+	assert.Contains(t, out.String(), "AnimeFire")
+	// The search page is the only request; no episode list is fetched
+	if assert.Len(t, doer.requests, 1) {
+		assert.Equal(t, "/pesquisar/naruto", doer.requests[0].URL.Path)
+	}
+}
+
+func TestSearchOnlyJSONRanksExactMatchFirst(t *testing.T) {
+	doer := &fakeDoer{bodies: map[string]string{
+		"https://animefire.plus/pesquisar/naruto": readFixture(t, "animefire_search.html"),
+	}}
+	defer api.SetHTTPDoer(doer)()
+
+	var out bytes.Buffer
+	assert.NoError(t, api.SearchOnly(&out, "naruto", true))
+
+	var results []api.SearchResult
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &results))
+	if assert.NotEmpty(t, results) {
+		assert.Equal(t, "Naruto", results[0].Name)
+		assert.Equal(t, "AnimeFire", results[0].Source)
+		assert.NotEmpty(t, results[0].URL)
+	}
+}
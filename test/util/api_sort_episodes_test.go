@@ -0,0 +1,32 @@
+package test_util_test
+
+import (
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortEpisodesNumericAware(t *testing.T) {
+	episodes := []api.Episode{
+		{Number: "Episódio 10"},
+		{Number: "OVA"},
+		{Number: "Episódio 2"},
+		{Number: "Episódio 7.5"},
+		{Number: "Especial"},
+		{Number: "Episódio 1"},
+		{Number: "Episódio 7"},
+	}
+
+	api.SortEpisodes(episodes)
+
+	var order []string
+	for _, episode := range episodes {
+		order = append(order, episode.Number)
+	}
+	assert.Equal(t, []string{
+		"Episódio 1", "Episódio 2", "Episódio 7", "Episódio 7.5", "Episódio 10",
+		// Unnumbered specials keep their listing order after the canon episodes
+		"OVA", "Especial",
+	}, order)
+}
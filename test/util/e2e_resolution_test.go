@@ -0,0 +1,45 @@
+package test_util_test
+
+import (
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/alvarorichard/Goanime/test/testserver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndToEndResolutionAgainstStubServer(t *testing.T) {
+	server := testserver.New()
+	defer api.SetHTTPDoer(server)()
+
+	results, err := api.SearchAnimeResults("stub anime")
+	assert.NoError(t, err)
+	if !assert.NotEmpty(t, results) {
+		return
+	}
+	anime := &results[0]
+	assert.Equal(t, "Stub Anime", anime.Name)
+	assert.Equal(t, testserver.AnimeURL, anime.URL)
+	assert.NoError(t, api.FetchAnimeDetails(anime))
+
+	episodes, err := api.GetAnimeEpisodes(anime.URL)
+	assert.NoError(t, err)
+	if !assert.Len(t, episodes, testserver.EpisodeCount) {
+		return
+	}
+	assert.Equal(t, 1, episodes[0].Num)
+	assert.Equal(t, testserver.EpisodeURL(1), episodes[0].URL)
+
+	series, total, err := api.IsSeries(anime.URL)
+	assert.NoError(t, err)
+	assert.True(t, series)
+	assert.Equal(t, testserver.EpisodeCount, total)
+
+	videoURL, err := player.GetVideoURLForEpisode(episodes[1].URL)
+	assert.NoError(t, err)
+	assert.Equal(t, testserver.StreamURL(2, "720p"), videoURL)
+
+	assert.Contains(t, server.Requests, "/pesquisar/stub anime")
+	assert.Contains(t, server.Requests, "/video/stub-anime/2")
+}
@@ -0,0 +1,31 @@
+package test_util_test
+
+import (
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdjacentEpisodeIndex(t *testing.T) {
+	tests := []struct {
+		name           string
+		current, step  int
+		count          int
+		expectedIndex  int
+		expectedMoving bool
+	}{
+		{"previous", 3, -1, 5, 2, true},
+		{"previous of the second", 1, -1, 5, 0, true},
+		{"previous of the first", 0, -1, 5, 0, false},
+		{"next", 3, 1, 5, 4, true},
+		{"next of the last", 4, 1, 5, 4, false},
+		{"empty queue", 0, -1, 0, 0, false},
+	}
+
+	for _, test := range tests {
+		index, ok := player.AdjacentEpisodeIndex(test.current, test.step, test.count)
+		assert.Equal(t, test.expectedIndex, index, test.name)
+		assert.Equal(t, test.expectedMoving, ok, test.name)
+	}
+}
@@ -0,0 +1,107 @@
+package test_util_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/alvarorichard/Goanime/test/testserver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchJobSurvivesARestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "batch.json")
+	job := player.NewBatchJob(path, "https://animefire.plus/animes/naruto-todos-os-episodios", 1, 20)
+	assert.NoError(t, job.Save())
+	for _, num := range []int{1, 2, 3, 5, 4, 6, 7} {
+		assert.NoError(t, job.MarkCompleted(num))
+	}
+	assert.NoError(t, job.MarkCompleted(3), "marking an episode twice is harmless")
+
+	loaded, err := player.LoadBatchJob(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://animefire.plus/animes/naruto-todos-os-episodios", loaded.AnimeURL)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7}, loaded.Completed)
+	assert.Equal(t, []int{8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}, loaded.Remaining())
+	assert.True(t, loaded.IsCompleted(5))
+	assert.False(t, loaded.IsCompleted(8))
+}
+
+func TestBatchJobFinishKeepsUnfinishedJobs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "batch.json")
+	job := player.NewBatchJob(path, "https://animefire.plus/animes/naruto-todos-os-episodios", 1, 2)
+	assert.NoError(t, job.MarkCompleted(1))
+
+	assert.NoError(t, job.Finish())
+	assert.FileExists(t, path)
+
+	assert.NoError(t, job.MarkCompleted(2))
+	assert.NoError(t, job.Finish())
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = player.LoadBatchJob(path)
+	assert.ErrorIs(t, err, player.ErrNoBatchJob)
+}
+
+func TestBatchDownloadRetriesEpisodesRejectedByVerify(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake ffprobe is a shell script")
+	}
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	// An ffprobe that rejects every file
+	bin := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "ffprobe"), []byte("#!/bin/sh\nexit 1\n"), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	// The size probe made before the downloads start fails, so they run without the progress bar, which needs a
+	// terminal. The file is then sent without a length, in a single stream that only -verify checks.
+	var probes int32
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead && atomic.AddInt32(&probes, 1) == 1 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		if r.Method != http.MethodHead {
+			_, _ = w.Write([]byte("not really a video"))
+		}
+		w.(http.Flusher).Flush()
+	}))
+	defer cdn.Close()
+
+	server := testserver.New()
+	defer api.SetHTTPDoer(server)()
+	server.Handle("/video/stub-anime/2", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data":[{"src":"%s/2.mp4","label":"720p"}]}`, cdn.URL)
+	})
+
+	config := util.DefaultConfig()
+	config.AllowPrivateIPs = true
+	config.Verify = true
+	config.Threads = 1
+	defer util.SetConfig(config)()
+
+	path, err := player.BatchJobPath()
+	assert.NoError(t, err)
+	job := player.NewBatchJob(path, testserver.AnimeURL, 2, 2)
+	assert.NoError(t, job.Save())
+
+	episodes := []api.Episode{{Number: "Episódio 2", Num: 2, URL: testserver.EpisodeURL(2)}}
+	assert.NoError(t, player.ResumeBatchDownload(episodes, job))
+
+	dir, err := player.AnimeDownloadDir(testserver.AnimeURL)
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, "2.mp4.broken"))
+	assert.Equal(t, []int{2}, job.Remaining())
+	assert.FileExists(t, path, "the job is kept for -resume-batch")
+}
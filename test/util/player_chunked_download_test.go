@@ -0,0 +1,76 @@
+package test_util_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadVideoFallsBackToSingleStreamWhenChunked(t *testing.T) {
+	content := strings.Repeat("episode data ", 10000)
+	var rangeRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			rangeRequests++
+		}
+		if r.Method == http.MethodHead {
+			return
+		}
+		// Flushing before the body is complete forces chunked transfer encoding, without a Content-Length
+		flusher := w.(http.Flusher)
+		for i := 0; i < len(content); i += 4096 {
+			end := min(i+4096, len(content))
+			_, _ = w.Write([]byte(content[i:end]))
+			flusher.Flush()
+		}
+	}))
+	defer server.Close()
+
+	config := util.DefaultConfig()
+	config.AllowPrivateIPs = true
+	config.ShowStats = true
+	defer util.SetConfig(config)()
+
+	destPath := filepath.Join(t.TempDir(), "1.mp4")
+	var err error
+	stdout := captureStdout(t, func() {
+		err = player.DownloadVideo(server.URL, destPath, 4, nil)
+	})
+
+	assert.NoError(t, err)
+	assert.Contains(t, stdout, "1.mp4: Downloaded 0.12 MB in", "-stats covers single-stream downloads")
+	data, err := os.ReadFile(destPath)
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(data))
+	assert.Zero(t, rangeRequests, "a file without a length isn't split into ranges")
+}
+
+// captureStdout returns what fn prints to the standard output.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- string(data)
+	}()
+	fn()
+	_ = w.Close()
+	return <-output
+}
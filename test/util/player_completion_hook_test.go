@@ -0,0 +1,37 @@
+package test_util_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHookArgsSubstitutesPlaceholders(t *testing.T) {
+	vars := player.HookVars{File: "/downloads/naruto/My Episode 3.mp4", Episode: "3", Anime: "naruto"}
+
+	args := player.HookArgs("mv {file} /media/{anime}/{episode}.mp4", vars)
+
+	assert.Equal(t, []string{"mv", "/downloads/naruto/My Episode 3.mp4", "/media/naruto/3.mp4"}, args)
+}
+
+func TestRunCompletionHookRunsCommand(t *testing.T) {
+	dir := t.TempDir()
+	episodePath := filepath.Join(dir, "3.mp4")
+	assert.NoError(t, os.WriteFile(episodePath, []byte("video"), 0644))
+
+	err := player.RunCompletionHook("cp {file} "+filepath.Join(dir, "{anime}-{episode}.mp4"),
+		player.HookVars{File: episodePath, Episode: "3", Anime: "naruto"})
+
+	assert.NoError(t, err)
+	copied, err := os.ReadFile(filepath.Join(dir, "naruto-3.mp4"))
+	assert.NoError(t, err)
+	assert.Equal(t, "video", string(copied))
+}
+
+func TestRunCompletionHookReportsFailure(t *testing.T) {
+	err := player.RunCompletionHook("false", player.HookVars{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,46 @@
+package test_util_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorruptCombinedFileFallsBackToYtDlp(t *testing.T) {
+	dir := t.TempDir()
+	destPath := filepath.Join(dir, "3.mp4")
+	assert.NoError(t, os.WriteFile(destPath, []byte("short"), 0o644))
+	assert.NoError(t, os.WriteFile(destPath+".part1", []byte("left over"), 0o644))
+
+	cause := player.CheckCombinedFile(destPath, 1024)
+	assert.True(t, errors.Is(cause, player.ErrCorruptDownload))
+
+	fallbackCalls := 0
+	err := player.RecoverCorruptDownload(destPath, 2, cause, func() error {
+		fallbackCalls++
+		// The corrupt file and its parts are gone before the fallback starts
+		_, statErr := os.Stat(destPath)
+		assert.True(t, os.IsNotExist(statErr))
+		_, statErr = os.Stat(destPath + ".part1")
+		assert.True(t, os.IsNotExist(statErr))
+		return os.WriteFile(destPath, make([]byte, 1024), 0o644)
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fallbackCalls)
+	assert.NoError(t, player.CheckCombinedFile(destPath, 1024))
+}
+
+func TestCorruptDownloadFallbackFailureKeepsCause(t *testing.T) {
+	destPath := filepath.Join(t.TempDir(), "3.mp4")
+	cause := player.CheckCombinedFile(destPath, 1024)
+
+	err := player.RecoverCorruptDownload(destPath, 4, cause, func() error { return errors.New("yt-dlp exited 1") })
+
+	assert.ErrorContains(t, err, "yt-dlp exited 1")
+	assert.ErrorContains(t, err, "combined download is corrupt")
+}
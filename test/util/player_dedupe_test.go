@@ -0,0 +1,130 @@
+package test_util_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDedupeIndexLinksIdenticalDownload(t *testing.T) {
+	dir := t.TempDir()
+	index, err := player.LoadDedupeIndex(filepath.Join(dir, "dedupe.json"))
+	assert.NoError(t, err)
+
+	first := filepath.Join(dir, "naruto", "1.mp4")
+	second := filepath.Join(dir, "naruto-classico", "1.mp4")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(first), 0755))
+	assert.NoError(t, os.MkdirAll(filepath.Dir(second), 0755))
+	assert.NoError(t, os.WriteFile(first, []byte("same episode"), 0644))
+	assert.NoError(t, os.WriteFile(second, []byte("same episode"), 0644))
+
+	linked, err := index.Record(first, "https://cdn.example/naruto/1.mp4")
+	assert.NoError(t, err)
+	assert.False(t, linked)
+
+	linked, err = index.Record(second, "https://cdn.example/naruto-classico/1.mp4")
+	assert.NoError(t, err)
+	assert.True(t, linked)
+
+	firstInfo, _ := os.Stat(first)
+	secondInfo, _ := os.Stat(second)
+	assert.True(t, os.SameFile(firstInfo, secondInfo))
+
+	// The index survives a reload
+	reloaded, err := player.LoadDedupeIndex(filepath.Join(dir, "dedupe.json"))
+	assert.NoError(t, err)
+	assert.Len(t, reloaded.Entries, 2)
+	assert.Equal(t, reloaded.Entries[0].Hash, reloaded.Entries[1].Hash)
+}
+
+func TestDedupeIndexKeepsDifferentFiles(t *testing.T) {
+	dir := t.TempDir()
+	index, _ := player.LoadDedupeIndex(filepath.Join(dir, "dedupe.json"))
+
+	first := filepath.Join(dir, "1.mp4")
+	second := filepath.Join(dir, "2.mp4")
+	assert.NoError(t, os.WriteFile(first, []byte("episode one"), 0644))
+	assert.NoError(t, os.WriteFile(second, []byte("episode two"), 0644))
+
+	_, err := index.Record(first, "")
+	assert.NoError(t, err)
+	linked, err := index.Record(second, "")
+	assert.NoError(t, err)
+	assert.False(t, linked)
+}
+
+func TestDedupeIndexLinksKnownSourceBeforeDownloading(t *testing.T) {
+	dir := t.TempDir()
+	index, _ := player.LoadDedupeIndex(filepath.Join(dir, "dedupe.json"))
+
+	first := filepath.Join(dir, "naruto", "1.mp4")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(first), 0755))
+	assert.NoError(t, os.WriteFile(first, []byte("episode"), 0644))
+	source := player.DedupeSource("https://cdn.example/naruto/1.mp4?token=abc")
+	_, err := index.Record(first, source)
+	assert.NoError(t, err)
+
+	dest := filepath.Join(dir, "naruto-classico", "1.mp4")
+	linked, err := index.LinkFromSource(player.DedupeSource("https://cdn.example/naruto/1.mp4?token=xyz"), dest)
+	assert.NoError(t, err)
+	assert.True(t, linked)
+
+	data, err := os.ReadFile(dest)
+	assert.NoError(t, err)
+	assert.Equal(t, "episode", string(data))
+
+	linked, err = index.LinkFromSource("https://cdn.example/naruto/2.mp4", filepath.Join(dir, "2.mp4"))
+	assert.NoError(t, err)
+	assert.False(t, linked)
+}
+
+func TestDedupeSourceKeepsIdentifyingQuery(t *testing.T) {
+	assert.Equal(t, "https://cdn.example/naruto/1.mp4", player.DedupeSource("https://cdn.example/naruto/1.mp4?token=abc"))
+	assert.Equal(t, "https://www.blogger.com/video.g?token=AD6v5dx", player.DedupeSource("https://www.blogger.com/video.g?token=AD6v5dx"))
+}
+
+func TestDedupeIndexRecordingAgainReplacesTheEntry(t *testing.T) {
+	dir := t.TempDir()
+	index, _ := player.LoadDedupeIndex(filepath.Join(dir, "dedupe.json"))
+
+	path := filepath.Join(dir, "1.mp4")
+	assert.NoError(t, os.WriteFile(path, []byte("episode one"), 0644))
+	_, err := index.Record(path, "")
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, []byte("episode one, fixed"), 0644))
+	_, err = index.Record(path, "https://cdn.example/1.mp4")
+	assert.NoError(t, err)
+
+	reloaded, err := player.LoadDedupeIndex(filepath.Join(dir, "dedupe.json"))
+	assert.NoError(t, err)
+	if assert.Len(t, reloaded.Entries, 1) {
+		assert.Equal(t, "https://cdn.example/1.mp4", reloaded.Entries[0].Source)
+	}
+}
+
+func TestDedupeIndexKeepsDownloadWhenLinkFails(t *testing.T) {
+	dir := t.TempDir()
+	index, _ := player.LoadDedupeIndex(filepath.Join(dir, "dedupe.json"))
+
+	first := filepath.Join(dir, "naruto", "1.mp4")
+	second := filepath.Join(dir, "naruto-classico", "1.mp4")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(first), 0755))
+	assert.NoError(t, os.MkdirAll(filepath.Dir(second), 0755))
+	assert.NoError(t, os.WriteFile(first, []byte("same episode"), 0644))
+	assert.NoError(t, os.WriteFile(second, []byte("same episode"), 0644))
+	_, err := index.Record(first, "")
+	assert.NoError(t, err)
+
+	// A non-empty directory where the link would be made can't be replaced, so the link fails
+	assert.NoError(t, os.MkdirAll(filepath.Join(second+".tmp", "busy"), 0755))
+	linked, err := index.Record(second, "")
+	assert.Error(t, err)
+	assert.False(t, linked)
+
+	data, err := os.ReadFile(second)
+	assert.NoError(t, err)
+	assert.Equal(t, "same episode", string(data))
+}
@@ -0,0 +1,49 @@
+package test_util_test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeldDownloadLockSkipsSecondAttempt(t *testing.T) {
+	episodePath := filepath.Join(t.TempDir(), "5.mp4")
+
+	release, err := player.AcquireDownloadLock(episodePath)
+	assert.NoError(t, err)
+
+	_, err = player.AcquireDownloadLock(episodePath)
+	assert.True(t, errors.Is(err, player.ErrDownloadLocked))
+
+	release()
+	_, err = os.Stat(episodePath + ".lock")
+	assert.True(t, os.IsNotExist(err))
+
+	releaseAgain, err := player.AcquireDownloadLock(episodePath)
+	assert.NoError(t, err, "a released lock can be taken again")
+	releaseAgain()
+}
+
+func TestStaleDownloadLockIsReclaimed(t *testing.T) {
+	// A process that has already exited stands in for a GoAnime that crashed mid-download
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("cannot start a helper process: %v", err)
+	}
+
+	episodePath := filepath.Join(t.TempDir(), "5.mp4")
+	assert.NoError(t, os.WriteFile(episodePath+".lock", []byte(strconv.Itoa(cmd.Process.Pid)), 0o644))
+
+	release, err := player.AcquireDownloadLock(episodePath)
+
+	assert.NoError(t, err)
+	data, _ := os.ReadFile(episodePath + ".lock")
+	assert.Equal(t, strconv.Itoa(os.Getpid())+"\n", string(data))
+	release()
+}
@@ -0,0 +1,25 @@
+package test_util_test
+
+import (
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreferredDownloaderFollowsSourceDeclaration(t *testing.T) {
+	url := "https://cdn.example.com/videos/ep1.mp4"
+	assert.Equal(t, player.DownloaderHTTP, player.PreferredDownloader(url))
+
+	restore := player.SetSourceDownloader("example.com", player.DownloaderYtDlp)
+	assert.Equal(t, player.DownloaderYtDlp, player.PreferredDownloader(url), "a declared source wins over the .mp4 URL")
+
+	restore()
+	assert.Equal(t, player.DownloaderHTTP, player.PreferredDownloader(url))
+}
+
+func TestPreferredDownloaderHeuristics(t *testing.T) {
+	assert.Equal(t, player.DownloaderYtDlp, player.PreferredDownloader("https://www.blogger.com/video.g?token=abc"))
+	assert.Equal(t, player.DownloaderYtDlp, player.PreferredDownloader("https://stream.example.net/hls/master.m3u8?sig=1"))
+	assert.Equal(t, player.DownloaderHTTP, player.PreferredDownloader("https://lightspeedst.net/s5/mp4/naruto/720p/1.mp4"))
+}
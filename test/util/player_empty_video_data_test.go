@@ -0,0 +1,48 @@
+package test_util_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/alvarorichard/Goanime/test/testserver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveRetriesEmptyVideoData(t *testing.T) {
+	server := testserver.New()
+	defer api.SetHTTPDoer(server)()
+
+	calls := 0
+	server.Handle("/video/stub-anime/2", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if calls == 1 {
+			fmt.Fprint(w, `{"data":[]}`)
+			return
+		}
+		fmt.Fprintf(w, `{"data":[{"src":"%s","label":"720p"}]}`, testserver.StreamURL(2, "720p"))
+	})
+
+	videoURL, err := player.ResolveVideoURL(testserver.EpisodeURL(2))
+	assert.NoError(t, err)
+	assert.Equal(t, testserver.StreamURL(2, "720p"), videoURL)
+	assert.Equal(t, 2, calls)
+}
+
+func TestResolveGivesUpOnEmptyVideoData(t *testing.T) {
+	server := testserver.New()
+	defer api.SetHTTPDoer(server)()
+
+	calls := 0
+	server.Handle("/video/stub-anime/2", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"data":[]}`)
+	})
+
+	_, err := player.ResolveVideoURL(testserver.EpisodeURL(2))
+	assert.ErrorContains(t, err, "no video data")
+	assert.Equal(t, 3, calls)
+}
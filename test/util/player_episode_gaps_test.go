@@ -0,0 +1,24 @@
+package test_util_test
+
+import (
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingEpisodesInRequestedRange(t *testing.T) {
+	var episodes []api.Episode
+	for num := 1; num <= 24; num++ {
+		if num != 13 {
+			episodes = append(episodes, api.Episode{Num: num})
+		}
+	}
+
+	missing := player.MissingEpisodes(episodes, 1, 30)
+
+	assert.Equal(t, []int{13, 25, 26, 27, 28, 29, 30}, missing)
+	assert.Equal(t, "13, 25-30", player.FormatEpisodeRanges(missing))
+	assert.Empty(t, player.MissingEpisodes(episodes, 14, 24))
+}
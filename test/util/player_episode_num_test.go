@@ -0,0 +1,93 @@
+package test_util_test
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/alvarorichard/Goanime/test/testserver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEpisodeIndexMatchesByNumNotLabel(t *testing.T) {
+	episodes := []api.Episode{
+		{Number: "Episódio 1", Num: 1},
+		{Number: "Episódio Final", Num: 25},
+	}
+
+	index, ok := player.EpisodeIndex(episodes, 25)
+	assert.True(t, ok)
+	assert.Equal(t, 1, index)
+
+	_, ok = player.EpisodeIndex(episodes, 2)
+	assert.False(t, ok)
+}
+
+func TestBatchDownloadFindsEpisodesLabelledWithoutANumber(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	server := testserver.New()
+	defer api.SetHTTPDoer(server)()
+	// Nothing is downloaded: the episode is already on disk, and its size can't be fetched
+	server.Handle("/video/stub-anime/25", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":[{"src":"http://127.0.0.1:1/25.mp4","label":"720p"}]}`)
+	})
+
+	episodes := []api.Episode{{Number: "Episódio Final", Num: 25, URL: testserver.EpisodeURL(25)}}
+	dir, err := player.AnimeDownloadDir(testserver.AnimeURL)
+	assert.NoError(t, err)
+	assert.NoError(t, os.MkdirAll(dir, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "25.mp4"), []byte("episode"), 0o644))
+
+	path, err := player.BatchJobPath()
+	assert.NoError(t, err)
+	job := player.NewBatchJob(path, testserver.AnimeURL, 25, 25)
+	assert.NoError(t, job.Save())
+
+	logs := captureLog(t)
+	assert.NoError(t, player.ResumeBatchDownload(episodes, job))
+
+	assert.NotContains(t, logs.String(), "not found")
+	assert.True(t, job.IsCompleted(25))
+	assert.NoFileExists(t, path, "the finished job is forgotten")
+}
+
+func TestPlayEpisodeMovieLabelledWithoutANumber(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake mpv is a shell script")
+	}
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	server := testserver.New()
+	defer api.SetHTTPDoer(server)()
+
+	// A fake mpv that records its arguments, so playback needs no player and no window
+	bin := t.TempDir()
+	argsFile := filepath.Join(bin, "args")
+	script := "#!/bin/sh\necho \"$@\" > \"" + argsFile + ".tmp\" && mv \"" + argsFile + ".tmp\" \"" + argsFile + "\"\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "mpv"), []byte(script), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	// No commands are typed, so playback returns as soon as mpv is started
+	stdin, err := os.Open(os.DevNull)
+	assert.NoError(t, err)
+	defer stdin.Close()
+	previousStdin := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = previousStdin }()
+
+	// The only episode of a movie, labelled without a number and numbered 3 by its URL
+	movie := []api.Episode{{Number: "Filme", Num: 3, URL: testserver.EpisodeURL(3)}}
+	videoURL := testserver.StreamURL(3, "720p")
+	assert.NoError(t, player.PlayEpisode(videoURL, movie, 0, testserver.AnimeURL, 0, nil))
+
+	assert.Eventually(t, func() bool {
+		args, err := os.ReadFile(argsFile)
+		return err == nil && strings.Contains(string(args), videoURL)
+	}, 5*time.Second, 50*time.Millisecond)
+}
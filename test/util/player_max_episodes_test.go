@@ -0,0 +1,45 @@
+package test_util_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfirmBatchSizeRejectsUnconfirmedHugeRange(t *testing.T) {
+	defer util.SetConfig(util.DefaultConfig())()
+
+	episodes := make([]api.Episode, 1000)
+	for i := range episodes {
+		episodes[i].Num = i + 1
+	}
+	count := player.CountEpisodesInRange(episodes, 1, 1000)
+	assert.Equal(t, 1000, count)
+
+	err := player.ConfirmBatchSize(count, nil)
+	assert.True(t, errors.Is(err, player.ErrTooManyEpisodes))
+
+	declined := player.ConfirmBatchSize(count, func(int) bool { return false })
+	assert.True(t, errors.Is(declined, player.ErrTooManyEpisodes))
+}
+
+func TestConfirmBatchSizeAllowsConfirmedOrSmallBatches(t *testing.T) {
+	defer util.SetConfig(util.DefaultConfig())()
+
+	assert.NoError(t, player.ConfirmBatchSize(util.DefaultMaxEpisodes, nil))
+	assert.NoError(t, player.ConfirmBatchSize(200, func(int) bool { return true }))
+
+	config := util.DefaultConfig()
+	config.Yes = true
+	util.SetConfig(config)
+	assert.NoError(t, player.ConfirmBatchSize(200, nil))
+
+	config = util.DefaultConfig()
+	config.MaxEpisodes = 0
+	util.SetConfig(config)
+	assert.NoError(t, player.ConfirmBatchSize(200, nil))
+}
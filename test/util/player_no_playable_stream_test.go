@@ -0,0 +1,70 @@
+package test_util_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/alvarorichard/Goanime/test/testserver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoPlayableStreamErrorListsAttempts(t *testing.T) {
+	errTimeout := errors.New("connection timed out")
+	err := error(&player.NoPlayableStreamError{Attempts: []player.StreamAttempt{
+		{Source: "AnimeFire", Server: player.ServerPlayer, Quality: "720p", Err: errors.New("invalid video URL")},
+		{Source: "AnimeFire", Server: player.ServerBlogger, Err: errTimeout},
+	}})
+
+	assert.True(t, errors.Is(err, player.ErrNoPlayableStream))
+	assert.True(t, errors.Is(err, errTimeout))
+	assert.Contains(t, err.Error(), "2 attempt(s)")
+	assert.Contains(t, err.Error(), "AnimeFire, player, 720p: invalid video URL")
+	assert.Contains(t, err.Error(), "AnimeFire, Blogger: connection timed out")
+
+	var noStream *player.NoPlayableStreamError
+	if assert.True(t, errors.As(err, &noStream)) {
+		assert.Equal(t, "No playable stream found. Tried:\n"+
+			"  1. AnimeFire, player, 720p: invalid video URL\n"+
+			"  2. AnimeFire, Blogger: connection timed out", noStream.Summary())
+	}
+}
+
+func TestResolveRecordsEveryFailedServer(t *testing.T) {
+	server := testserver.New()
+	defer api.SetHTTPDoer(server)()
+
+	server.Handle("/video/stub-anime/2", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusInternalServerError)
+	})
+
+	_, err := player.ResolveVideoURL(testserver.EpisodeURL(2))
+	assert.True(t, errors.Is(err, player.ErrNoPlayableStream), "got %v", err)
+
+	var noStream *player.NoPlayableStreamError
+	if assert.True(t, errors.As(err, &noStream)) && assert.Len(t, noStream.Attempts, 1) {
+		assert.Equal(t, api.SourceName, noStream.Attempts[0].Source)
+		assert.Equal(t, player.ServerPlayer, noStream.Attempts[0].Server)
+		assert.ErrorContains(t, noStream.Attempts[0].Err, "500")
+	}
+}
+
+func TestResolveFallsBackToBloggerEmbed(t *testing.T) {
+	server := testserver.New()
+	defer api.SetHTTPDoer(server)()
+
+	bloggerURL := "https://www.blogger.com/video.g?token=stub-token"
+	server.Handle("/animes/stub-anime/3", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<html><body><video data-video-src="%s"></video><iframe src="%s"></iframe></body></html>`, testserver.VideoAPIURL(3), bloggerURL)
+	})
+	server.Handle("/video/stub-anime/3", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusInternalServerError)
+	})
+
+	videoURL, err := player.ResolveVideoURL(testserver.EpisodeURL(3))
+	assert.NoError(t, err)
+	assert.Equal(t, bloggerURL, videoURL)
+}
@@ -0,0 +1,38 @@
+package test_util_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLastDownloadedEpisode(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"1.mp4", "2.mp4", "10.mp4", "11.mp4.part0", "notes.txt"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+
+	last, found := player.LastDownloadedEpisode(dir)
+	assert.True(t, found)
+	assert.Equal(t, 10, last)
+
+	_, found = player.LastDownloadedEpisode(filepath.Join(dir, "missing"))
+	assert.False(t, found)
+}
+
+func TestEpisodesAfterLastDownloaded(t *testing.T) {
+	episodes := []api.Episode{{Num: 1}, {Num: 2}, {Num: 3}, {Num: 4}}
+
+	assert.Equal(t, []api.Episode{{Num: 3}, {Num: 4}}, player.EpisodesAfter(episodes, 2))
+	assert.Empty(t, player.EpisodesAfter(episodes, 4))
+	assert.Len(t, player.EpisodesAfter(episodes, 0), 4)
+}
+
+func TestDownloadFolderFormatterHandlesAnimePages(t *testing.T) {
+	assert.Equal(t, "naruto-todos-os-episodios", player.DownloadFolderFormatter("https://animefire.plus/animes/naruto-todos-os-episodios"))
+	assert.Equal(t, "abc123", player.DownloadFolderFormatter("https://animefire.plus/video/abc123"))
+}
@@ -0,0 +1,22 @@
+package test_util_test
+
+import (
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenCommandPerOS(t *testing.T) {
+	videoURL := "https://cdn.example.com/ep1.mp4?token=a&b=c"
+
+	for goos, want := range map[string][]string{
+		"linux":   {"xdg-open", videoURL},
+		"freebsd": {"xdg-open", videoURL},
+		"darwin":  {"open", videoURL},
+		"windows": {"cmd", "/c", "start", "", videoURL},
+	} {
+		name, args := player.OpenCommand(goos, videoURL)
+		assert.Equal(t, want, append([]string{name}, args...), goos)
+	}
+}
@@ -0,0 +1,63 @@
+package test_util_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+const testOutputTemplate = "{anime}/S{season:02d}/{anime} - S{season:02d}E{episode:02d} - {title}.{ext}"
+
+func TestRenderOutputTemplate(t *testing.T) {
+	info := player.EpisodeFileInfo{Anime: "naruto", Season: 2, Episode: 7, Title: "Kakashi: Sharingan Warrior?", Ext: "mp4"}
+
+	got := player.RenderOutputTemplate(testOutputTemplate, info)
+
+	assert.Equal(t, filepath.Join("naruto", "S02", "naruto - S02E07 - Kakashi_ Sharingan Warrior_.mp4"), got)
+}
+
+func TestRenderOutputTemplateFillsMissingFields(t *testing.T) {
+	got := player.RenderOutputTemplate(testOutputTemplate, player.EpisodeFileInfo{Episode: 3})
+
+	assert.Equal(t, filepath.Join("unknown", "S01", "unknown - S01E03 - Episode 3.mp4"), got)
+}
+
+func TestRenderOutputTemplateStaysInsideDownloads(t *testing.T) {
+	info := player.EpisodeFileInfo{Anime: "../../etc", Episode: 1, Title: "a/b"}
+
+	got := player.RenderOutputTemplate("../{anime}/{title}.{ext}", info)
+
+	assert.Equal(t, filepath.Join(".._.._etc", "a_b.mp4"), got)
+}
+
+func TestEpisodeFilePath(t *testing.T) {
+	downloadPath := filepath.Join("downloads", "anime", "naruto")
+	episode := api.Episode{Num: 4, Title: api.TitleDetails{English: "Hatake Kakashi"}}
+
+	assert.Equal(t, filepath.Join(downloadPath, "4.mp4"), player.EpisodeFilePath(downloadPath, "4", episode))
+
+	config := util.DefaultConfig()
+	config.OutputTemplate = "{anime}/{episode:03d} {title}.{ext}"
+	defer util.SetConfig(config)()
+
+	assert.Equal(t, filepath.Join(downloadPath, "004 Hatake Kakashi.mp4"), player.EpisodeFilePath(downloadPath, "4", episode))
+}
+
+func TestEpisodeFilePathRendersUnderTheAnimeFolder(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	downloadPath, err := player.AnimeDownloadDir("https://animefire.plus/animes/naruto-todos-os-episodios")
+	assert.NoError(t, err)
+
+	config := util.DefaultConfig()
+	config.OutputTemplate = "{anime}/{anime} - E{episode:02d}.{ext}"
+	defer util.SetConfig(config)()
+
+	got := player.EpisodeFilePath(downloadPath, "4", api.Episode{Num: 4})
+	want := filepath.Join(dataHome, "goanime", "downloads", "anime", "naruto-todos-os-episodios", "naruto-todos-os-episodios - E04.mp4")
+	assert.Equal(t, want, got)
+}
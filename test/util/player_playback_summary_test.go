@@ -0,0 +1,20 @@
+package test_util_test
+
+import (
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlaybackSummary(t *testing.T) {
+	assert.Equal(t, "Playing Naruto E5 from AnimeFire [720p, sub]", player.PlaybackSummary(player.PlaybackInfo{
+		Anime: "Naruto", Episode: 5, Source: api.SourceName, Quality: "720p", Audio: player.AudioLabel("Naruto"),
+	}))
+
+	// Movies have no episode number and Blogger videos no known quality
+	assert.Equal(t, "Playing Your Name (Dublado) from AnimeFire [dub]", player.PlaybackSummary(player.PlaybackInfo{
+		Anime: "Your Name (Dublado)", Source: api.SourceName, Audio: player.AudioLabel("Your Name (Dublado)"),
+	}))
+}
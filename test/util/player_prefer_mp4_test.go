@@ -0,0 +1,39 @@
+package test_util_test
+
+import (
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreferMP4PicksProgressiveOverHigherHLS(t *testing.T) {
+	videos := []player.VideoData{
+		{Src: "https://cdn.example.com/naruto/1/1080p/master.m3u8", Label: "1080p"},
+		{Src: "https://cdn.example.com/naruto/1/720p.mp4", Label: "720p"},
+	}
+
+	src, err := player.NewQualitySelector(nil).Select(videos, false)
+	assert.NoError(t, err)
+	assert.Equal(t, videos[0].Src, src, "the highest quality wins by default")
+
+	config := util.DefaultConfig()
+	config.PreferMP4 = true
+	defer util.SetConfig(config)()
+
+	src, err = player.NewQualitySelector(nil).Select(videos, false)
+	assert.NoError(t, err)
+	assert.Equal(t, videos[1].Src, src)
+}
+
+func TestPreferMP4FallsBackToHLS(t *testing.T) {
+	config := util.DefaultConfig()
+	config.PreferMP4 = true
+	defer util.SetConfig(config)()
+
+	videos := []player.VideoData{{Src: "https://cdn.example.com/naruto/1/master.m3u8", Label: "1080p"}}
+	src, err := player.NewQualitySelector(nil).Select(videos, false)
+	assert.NoError(t, err)
+	assert.Equal(t, videos[0].Src, src)
+}
@@ -0,0 +1,80 @@
+package test_util_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefetchWindow(t *testing.T) {
+	assert.Equal(t, []int{3, 4}, player.PrefetchWindow(2, 10, 2))
+	assert.Equal(t, []int{9}, player.PrefetchWindow(8, 10, 3))
+	assert.Empty(t, player.PrefetchWindow(9, 10, 3))
+	assert.Empty(t, player.PrefetchWindow(0, 10, 0))
+}
+
+func TestPrefetcherResolvesAtMostNAhead(t *testing.T) {
+	var mu sync.Mutex
+	var resolved []string
+	prefetcher := player.NewPrefetcher(func(episodeURL string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		resolved = append(resolved, episodeURL)
+		return episodeURL + ".mp4", nil
+	}, func(string) bool { return true }, time.Minute)
+
+	var episodes []api.Episode
+	for i := 1; i <= 6; i++ {
+		episodes = append(episodes, api.Episode{Num: i, URL: fmt.Sprintf("ep%d", i)})
+	}
+
+	prefetcher.Schedule(episodes, 1, 2)
+	prefetcher.Wait()
+	assert.ElementsMatch(t, []string{"ep3", "ep4"}, resolved)
+
+	// Scheduling again from the next episode only resolves the one new entry in the window
+	prefetcher.Schedule(episodes, 2, 2)
+	prefetcher.Wait()
+	assert.ElementsMatch(t, []string{"ep3", "ep4", "ep5"}, resolved)
+	assert.Equal(t, 3, prefetcher.Resolved())
+
+	videoURL, err := prefetcher.VideoURL("ep4")
+	assert.NoError(t, err)
+	assert.Equal(t, "ep4.mp4", videoURL)
+	assert.Len(t, resolved, 3)
+}
+
+func TestPrefetcherResolvesStaleURLsAgain(t *testing.T) {
+	calls := 0
+	resolve := func(episodeURL string) (string, error) {
+		calls++
+		return fmt.Sprintf("%s.mp4?call=%d", episodeURL, calls), nil
+	}
+	episodes := []api.Episode{{Num: 1, URL: "ep1"}, {Num: 2, URL: "ep2"}}
+
+	// Expired: the token of a URL prefetched a whole episode ago is no longer valid
+	expiring := player.NewPrefetcher(resolve, func(string) bool { return true }, time.Millisecond)
+	expiring.Schedule(episodes, 0, 1)
+	expiring.Wait()
+	time.Sleep(5 * time.Millisecond)
+	videoURL, err := expiring.VideoURL("ep2")
+	assert.NoError(t, err)
+	assert.Equal(t, "ep2.mp4?call=2", videoURL)
+
+	// Unreachable, even though it is still fresh
+	calls = 0
+	unreachable := player.NewPrefetcher(resolve, func(string) bool { return false }, time.Minute)
+	unreachable.Schedule(episodes, 0, 1)
+	unreachable.Wait()
+	_, ok := unreachable.Prefetched("ep2")
+	assert.False(t, ok)
+	assert.Equal(t, 0, unreachable.Resolved())
+	videoURL, err = unreachable.VideoURL("ep2")
+	assert.NoError(t, err)
+	assert.Equal(t, "ep2.mp4?call=2", videoURL)
+}
@@ -0,0 +1,78 @@
+package test_util_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatCommandMatchesYtDlpArgs(t *testing.T) {
+	videoURL := "https://www.blogger.com/video.g?token=AD6v5dx"
+	args := player.YtDlpArgs("/home/user/downloads/1.mp4", videoURL)
+
+	assert.Equal(t, []string{"--continue", "--no-progress", "--concurrent-fragments", "4", "-o", "/home/user/downloads/1.mp4", videoURL}, args)
+
+	printed := player.FormatCommand("yt-dlp", args, false)
+	assert.Equal(t, "yt-dlp --continue --no-progress --concurrent-fragments 4 -o /home/user/downloads/1.mp4 'https://www.blogger.com/video.g?token=AD6v5dx'", printed)
+}
+
+func TestFormatCommandMatchesMPVArgs(t *testing.T) {
+	args := player.MPVArgs("https://cdn.example.com/ep 1.mp4", "/tmp/goanime_mpvsocket_ab12", []string{"--script-opts=skip_op=10-100"})
+
+	printed := player.FormatCommand("mpv", args, false)
+
+	assert.Equal(t, "mpv --no-terminal --quiet --input-ipc-server=/tmp/goanime_mpvsocket_ab12 'https://cdn.example.com/ep 1.mp4' --script-opts=skip_op=10-100", printed)
+}
+
+func TestFormatCommandRedactsStreamHost(t *testing.T) {
+	args := player.YtDlpArgs("/tmp/1.mp4", "https://www.blogger.com/video.g?token=AD6v5dx")
+
+	printed := player.FormatCommand("yt-dlp", args, true)
+
+	assert.False(t, strings.Contains(printed, "www.blogger.com"))
+	assert.Contains(t, printed, "https://REDACTED/video.g?token=AD6v5dx")
+	assert.Contains(t, printed, "/tmp/1.mp4")
+}
+
+func TestMPVArgsIsolatedProfile(t *testing.T) {
+	config := util.DefaultConfig()
+	config.MPVProfile = util.MPVProfileIsolated
+	defer util.SetConfig(config)()
+
+	args := player.MPVArgs("https://cdn.example.com/1.mp4", "/tmp/goanime_mpvsocket_ab12", nil)
+
+	assert.Equal(t, "--no-config", args[0])
+	assert.Contains(t, args, "--input-ipc-server=/tmp/goanime_mpvsocket_ab12")
+	assert.Contains(t, args, "https://cdn.example.com/1.mp4")
+}
+
+func TestMPVArgsDefaultProfileKeepsUserConfig(t *testing.T) {
+	args := player.MPVArgs("https://cdn.example.com/1.mp4", "/tmp/goanime_mpvsocket_ab12", nil)
+
+	assert.NotContains(t, args, "--no-config")
+}
+
+func TestMPVArgsSubtitleStyling(t *testing.T) {
+	config := util.DefaultConfig()
+	config.SubScale = 1.5
+	config.SubColor = "#FFFF00"
+	config.SubPos = 90
+	defer util.SetConfig(config)()
+
+	args := player.MPVArgs("https://cdn.example.com/1.mp4", "/tmp/goanime_mpvsocket_ab12", nil)
+
+	assert.Contains(t, args, "--sub-scale=1.5")
+	assert.Contains(t, args, "--sub-color=#FFFF00")
+	assert.Contains(t, args, "--sub-pos=90")
+}
+
+func TestMPVArgsDefaultSubtitlesAreLeftToMPV(t *testing.T) {
+	args := player.MPVArgs("https://cdn.example.com/1.mp4", "/tmp/goanime_mpvsocket_ab12", nil)
+
+	for _, arg := range args {
+		assert.False(t, strings.HasPrefix(arg, "--sub-"), arg)
+	}
+}
@@ -0,0 +1,30 @@
+package test_util_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQualityFilePath(t *testing.T) {
+	assert.Equal(t, filepath.Join("anime", "naruto", "5.1080p.mp4"), player.QualityFilePath(filepath.Join("anime", "naruto", "5.mp4"), "1080p"))
+	assert.Equal(t, filepath.Join("anime", "naruto", "5.mp4"), player.QualityFilePath(filepath.Join("anime", "naruto", "5.mp4"), ""))
+}
+
+func TestQualityFilePathsExistPerQuality(t *testing.T) {
+	dir := t.TempDir()
+	episodePath := filepath.Join(dir, "5.mp4")
+	assert.NoError(t, os.WriteFile(player.QualityFilePath(episodePath, "720p"), nil, 0o644))
+
+	_, err := os.Stat(player.QualityFilePath(episodePath, "720p"))
+	assert.NoError(t, err)
+	_, err = os.Stat(player.QualityFilePath(episodePath, "1080p"))
+	assert.True(t, os.IsNotExist(err), "a 720p download doesn't count as the 1080p one")
+
+	last, found := player.LastDownloadedEpisode(dir)
+	assert.True(t, found)
+	assert.Equal(t, 5, last)
+}
@@ -0,0 +1,124 @@
+package test_util_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/alvarorichard/Goanime/test/testserver"
+	"github.com/stretchr/testify/assert"
+)
+
+var qualityFixture = []player.VideoData{
+	{Src: "https://cdn.example/360.mp4", Label: "360p"},
+	{Src: "https://cdn.example/1080.mp4", Label: "1080p"},
+	{Src: "https://cdn.example/720.mp4", Label: "720p"},
+}
+
+func TestGetAvailableQualitiesHighestFirst(t *testing.T) {
+	assert.Equal(t, []string{"1080p", "720p", "360p"}, player.GetAvailableQualities(qualityFixture))
+}
+
+func TestQualitySelectorReusesRememberedQuality(t *testing.T) {
+	prompts := 0
+	selector := player.NewQualitySelector(func(labels []string) (string, error) {
+		prompts++
+		assert.Equal(t, []string{"1080p", "720p", "360p"}, labels)
+		return "720p", nil
+	})
+
+	src, err := selector.Select(qualityFixture, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://cdn.example/720.mp4", src)
+	assert.Equal(t, "720p", selector.Remembered())
+
+	nextEpisode := []player.VideoData{
+		{Src: "https://cdn.example/ep2-720.mp4", Label: "720p"},
+		{Src: "https://cdn.example/ep2-1080.mp4", Label: "1080p"},
+	}
+	src, err = selector.Select(nextEpisode, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://cdn.example/ep2-720.mp4", src)
+	assert.Equal(t, 1, prompts)
+}
+
+func TestQualitySelectorPicksHighestWithoutAsking(t *testing.T) {
+	selector := player.NewQualitySelector(func(labels []string) (string, error) {
+		t.Fatal("the user must not be prompted")
+		return "", nil
+	})
+
+	src, err := selector.Select(qualityFixture, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://cdn.example/1080.mp4", src)
+	assert.Empty(t, selector.Remembered())
+}
+
+func TestQualitySelectorSelectsInBackgroundWhileAsking(t *testing.T) {
+	prompting := make(chan struct{})
+	answer := make(chan string)
+	selector := player.NewQualitySelector(func(labels []string) (string, error) {
+		close(prompting)
+		return <-answer, nil
+	})
+
+	chosen := make(chan string)
+	go func() {
+		src, _ := selector.Select(qualityFixture, true)
+		chosen <- src
+	}()
+	<-prompting
+
+	// A prefetch never prompts, and must not wait for the user to answer
+	selected := make(chan string)
+	go func() {
+		src, _ := selector.Select(qualityFixture, false)
+		selected <- src
+	}()
+	select {
+	case src := <-selected:
+		assert.Equal(t, "https://cdn.example/1080.mp4", src)
+	case <-time.After(time.Second):
+		t.Fatal("the background selection waited for the prompt")
+	}
+
+	answer <- "720p"
+	assert.Equal(t, "https://cdn.example/720.mp4", <-chosen)
+	assert.Equal(t, "720p", selector.Remembered())
+}
+
+func TestNextEpisodeAsksWhenRememberedQualityIsMissing(t *testing.T) {
+	server := testserver.New()
+	defer api.SetHTTPDoer(server)()
+	// Episode 2 isn't offered in 360p
+	server.Handle("/video/stub-anime/2", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data":[{"src":"%s","label":"480p"},{"src":"%s","label":"720p"}]}`,
+			testserver.StreamURL(2, "480p"), testserver.StreamURL(2, "720p"))
+	})
+
+	config := util.DefaultConfig()
+	config.AskQuality = true
+	defer util.SetConfig(config)()
+
+	var asked [][]string
+	answers := []string{"360p", "480p"}
+	defer player.SetQualityPrompt(func(labels []string) (string, error) {
+		asked = append(asked, labels)
+		answer := answers[0]
+		answers = answers[1:]
+		return answer, nil
+	})()
+
+	videoURL, err := player.GetVideoURLForEpisode(testserver.EpisodeURL(1))
+	assert.NoError(t, err)
+	assert.Equal(t, testserver.StreamURL(1, "360p"), videoURL)
+
+	videoURL, err = player.AdjacentEpisodeVideoURL(testserver.EpisodeURL(2))
+	assert.NoError(t, err)
+	assert.Equal(t, testserver.StreamURL(2, "480p"), videoURL)
+	assert.Equal(t, [][]string{{"720p", "360p"}, {"720p", "480p"}}, asked)
+}
@@ -0,0 +1,62 @@
+package test_util_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterEnforcesCap(t *testing.T) {
+	const rate = 200 * 1024 // 200 KB/s
+	limiter := player.NewRateLimiter(rate)
+
+	start := time.Now()
+	n, err := io.Copy(io.Discard, limiter.Reader(bytes.NewReader(make([]byte, 100*1024))))
+	elapsed := time.Since(start)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100*1024), n)
+	// 100 KB at 200 KB/s takes half a second
+	assert.GreaterOrEqual(t, elapsed, 450*time.Millisecond)
+	assert.Less(t, elapsed, 2*time.Second)
+}
+
+func TestRateLimiterIsSharedBetweenReaders(t *testing.T) {
+	const rate = 400 * 1024 // 400 KB/s
+	limiter := player.NewRateLimiter(rate)
+
+	start := time.Now()
+	done := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, _ = io.Copy(io.Discard, limiter.Reader(bytes.NewReader(make([]byte, 100*1024))))
+			done <- struct{}{}
+		}()
+	}
+	<-done
+	<-done
+
+	// Two readers of 100 KB sharing 400 KB/s take half a second, not a quarter
+	assert.GreaterOrEqual(t, time.Since(start), 450*time.Millisecond)
+}
+
+func TestYtDlpArgsPassMaxRate(t *testing.T) {
+	config := util.DefaultConfig()
+	config.MaxRate = 1.5
+	defer util.SetConfig(config)()
+
+	args := player.YtDlpArgs("/downloads/naruto/3.mp4", "https://www.blogger.com/video.g?token=abc")
+
+	for i, arg := range args {
+		if arg == "--limit-rate" {
+			assert.Equal(t, "1.5M", args[i+1])
+			return
+		}
+	}
+	t.Fatalf("--limit-rate missing from %v", args)
+}
@@ -0,0 +1,38 @@
+package test_util_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/alvarorichard/Goanime/test/testserver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveTimeoutStopsAStuckResolver(t *testing.T) {
+	server := testserver.New()
+	defer api.SetHTTPDoer(server)()
+	server.Handle("/video/stub-anime/3", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+
+	config := util.DefaultConfig()
+	config.ResolveTimeout = 100 * time.Millisecond
+	defer util.SetConfig(config)()
+
+	start := time.Now()
+	_, err := player.ResolveVideoURL(testserver.EpisodeURL(3))
+	assert.True(t, errors.Is(err, player.ErrResolveTimeout), "got %v", err)
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	videoURL, err := player.ResolveVideoURL(testserver.EpisodeURL(1))
+	assert.NoError(t, err)
+	assert.Equal(t, testserver.StreamURL(1, "720p"), videoURL)
+}
@@ -0,0 +1,54 @@
+package test_util_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/stretchr/testify/assert"
+)
+
+const resumeAnimeURL = "https://animefire.plus/animes/naruto-todos-os-episodios"
+
+func TestResumePositionsSurviveARestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resume.json")
+	positions, err := player.LoadResumePositions(path)
+	assert.NoError(t, err)
+	_, ok := positions.Position(resumeAnimeURL, 5)
+	assert.False(t, ok, "nothing is saved before the first episode is played")
+
+	assert.NoError(t, positions.Update(resumeAnimeURL, 5, 754*time.Second, 24*time.Minute))
+	assert.NoError(t, positions.Update(resumeAnimeURL, 6, 30*time.Second, 0))
+
+	loaded, err := player.LoadResumePositions(path)
+	assert.NoError(t, err)
+	seconds, ok := loaded.Position(resumeAnimeURL, 5)
+	assert.True(t, ok)
+	assert.Equal(t, 754.0, seconds)
+	seconds, ok = loaded.Position(resumeAnimeURL+"/", 6)
+	assert.True(t, ok, "a trailing slash names the same anime")
+	assert.Equal(t, 30.0, seconds)
+	_, ok = loaded.Position(resumeAnimeURL, 7)
+	assert.False(t, ok)
+}
+
+func TestResumePositionForgottenNearTheEnd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resume.json")
+	positions, err := player.LoadResumePositions(path)
+	assert.NoError(t, err)
+
+	assert.NoError(t, positions.Update(resumeAnimeURL, 5, 10*time.Minute, 24*time.Minute))
+	assert.NoError(t, positions.Update(resumeAnimeURL, 5, 24*time.Minute-player.ResumeFinishedMargin+time.Second, 24*time.Minute))
+
+	loaded, err := player.LoadResumePositions(path)
+	assert.NoError(t, err)
+	_, ok := loaded.Position(resumeAnimeURL, 5)
+	assert.False(t, ok)
+}
+
+func TestResumeArgs(t *testing.T) {
+	assert.Equal(t, []string{"--start=754"}, player.ResumeArgs(754.8))
+	assert.Equal(t, "12:34", player.FormatPosition(754.8))
+	assert.Equal(t, "1:02:03", player.FormatPosition(3723))
+}
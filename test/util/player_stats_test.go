@@ -0,0 +1,31 @@
+package test_util_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadStatsThroughput(t *testing.T) {
+	stats := player.DownloadStats{Bytes: 50 * 1024 * 1024, Elapsed: 10 * time.Second}
+	assert.InDelta(t, 5.0, stats.Throughput(), 0.0001)
+
+	stats = player.DownloadStats{Bytes: 1024, Elapsed: 0}
+	assert.Equal(t, 0.0, stats.Throughput())
+}
+
+func TestDownloadStatsReportsPerThreadShare(t *testing.T) {
+	stats := player.DownloadStats{
+		Bytes:     4 * 1024 * 1024,
+		Elapsed:   2 * time.Second,
+		PartBytes: []int64{3 * 1024 * 1024, 1024 * 1024},
+	}
+
+	report := stats.String()
+
+	assert.Contains(t, report, "Downloaded 4.00 MB in 2s (2.00 MB/s)")
+	assert.Contains(t, report, "thread 0: 3.00 MB (75.0%)")
+	assert.Contains(t, report, "thread 1: 1.00 MB (25.0%)")
+}
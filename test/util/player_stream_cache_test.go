@@ -0,0 +1,72 @@
+package test_util_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingResolver resolves every episode to a new URL and counts the calls.
+type countingResolver struct {
+	calls int
+}
+
+func (r *countingResolver) resolve(episodeURL string) (string, error) {
+	r.calls++
+	if episodeURL == "broken" {
+		return "", errors.New("no video")
+	}
+	return fmt.Sprintf("%s/video.mp4?call=%d", episodeURL, r.calls), nil
+}
+
+func TestStreamCacheReusesValidURL(t *testing.T) {
+	resolver := &countingResolver{}
+	probes := 0
+	cache := player.NewStreamCache(resolver.resolve, func(string) bool { probes++; return true }, time.Minute)
+
+	first, err := cache.VideoURL("https://animefire.plus/animes/naruto/1")
+	assert.NoError(t, err)
+	second, err := cache.VideoURL("https://animefire.plus/animes/naruto/1")
+	assert.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, resolver.calls)
+	assert.Equal(t, 1, probes)
+}
+
+func TestStreamCacheResolvesExpiredURLAgain(t *testing.T) {
+	resolver := &countingResolver{}
+	cache := player.NewStreamCache(resolver.resolve, func(string) bool { return true }, time.Millisecond)
+
+	first, _ := cache.VideoURL("https://animefire.plus/animes/naruto/1")
+	time.Sleep(5 * time.Millisecond)
+	second, _ := cache.VideoURL("https://animefire.plus/animes/naruto/1")
+
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, 2, resolver.calls)
+}
+
+func TestStreamCacheResolvesUnreachableURLAgain(t *testing.T) {
+	resolver := &countingResolver{}
+	cache := player.NewStreamCache(resolver.resolve, func(string) bool { return false }, time.Minute)
+
+	_, _ = cache.VideoURL("https://animefire.plus/animes/naruto/1")
+	_, _ = cache.VideoURL("https://animefire.plus/animes/naruto/1")
+
+	assert.Equal(t, 2, resolver.calls)
+}
+
+func TestStreamCacheDoesNotCacheFailures(t *testing.T) {
+	resolver := &countingResolver{}
+	cache := player.NewStreamCache(resolver.resolve, func(string) bool { return true }, time.Minute)
+
+	_, err := cache.VideoURL("broken")
+	assert.Error(t, err)
+	_, err = cache.VideoURL("broken")
+	assert.Error(t, err)
+	assert.Equal(t, 2, resolver.calls)
+}
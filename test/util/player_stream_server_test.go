@@ -0,0 +1,141 @@
+package test_util_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrowingFileServerServesClosedRangeOfPartialFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "1.mp4.part")
+	assert.NoError(t, os.WriteFile(path, []byte("0123456789"), 0644))
+
+	server := httptest.NewServer(player.NewGrowingFileServer(path))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	req.Header.Set("Range", "bytes=2-5")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusPartialContent, resp.StatusCode)
+	assert.Equal(t, "bytes 2-5/*", resp.Header.Get("Content-Range"))
+	assert.Equal(t, "2345", string(body))
+}
+
+func TestGrowingFileServerWaitsForRangeNotYetWritten(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "1.mp4.part")
+	assert.NoError(t, os.WriteFile(path, []byte("01234"), 0644))
+
+	server := httptest.NewServer(player.NewGrowingFileServer(path))
+	defer server.Close()
+
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		_, _ = f.WriteString("56789")
+		_ = f.Close()
+	}()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	req.Header.Set("Range", "bytes=6-8")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusPartialContent, resp.StatusCode)
+	assert.Equal(t, "678", string(body))
+}
+
+func TestGrowingFileServerStreamsWholeFileUntilFinished(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "1.mp4")
+	assert.NoError(t, os.WriteFile(path, []byte("abc"), 0644))
+
+	fileServer := player.NewGrowingFileServer(path)
+	server := httptest.NewServer(fileServer)
+	defer server.Close()
+
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		_, _ = f.WriteString("def")
+		_ = f.Close()
+		fileServer.Finish(nil)
+	}()
+
+	resp, err := http.Get(server.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "abcdef", string(body))
+}
+
+func TestGrowingFileServerSupportsRangesAfterFinish(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "1.mp4")
+	assert.NoError(t, os.WriteFile(path, []byte("0123456789"), 0644))
+
+	fileServer := player.NewGrowingFileServer(path)
+	fileServer.Finish(nil)
+	server := httptest.NewServer(fileServer)
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	req.Header.Set("Range", "bytes=7-")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusPartialContent, resp.StatusCode)
+	assert.Equal(t, "bytes 7-9/10", resp.Header.Get("Content-Range"))
+	assert.Equal(t, "789", string(body))
+}
+
+func TestGrowingFileServerFollowsOpenRangeUntilFinished(t *testing.T) {
+	for _, tc := range []struct {
+		rangeHeader string
+		status      int
+		body        string
+	}{
+		{"bytes=0-", http.StatusOK, "abcdef"},
+		{"bytes=2-", http.StatusPartialContent, "cdef"},
+	} {
+		path := filepath.Join(t.TempDir(), "1.mp4.part")
+		assert.NoError(t, os.WriteFile(path, []byte("abc"), 0644))
+
+		fileServer := player.NewGrowingFileServer(path)
+		server := httptest.NewServer(fileServer)
+
+		go func() {
+			time.Sleep(300 * time.Millisecond)
+			f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+			_, _ = f.WriteString("def")
+			_ = f.Close()
+			fileServer.Finish(nil)
+		}()
+
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		req.Header.Set("Range", tc.rangeHeader)
+		resp, err := http.DefaultClient.Do(req)
+		if assert.NoError(t, err, tc.rangeHeader) {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			assert.Equal(t, tc.status, resp.StatusCode, tc.rangeHeader)
+			assert.Equal(t, int64(-1), resp.ContentLength, tc.rangeHeader)
+			assert.Equal(t, tc.body, string(body), tc.rangeHeader)
+		}
+		server.Close()
+	}
+}
@@ -0,0 +1,39 @@
+package test_util_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/alvarorichard/Goanime/test/testserver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFFmpegCoverArgsEmbedsPosterAsCoverArt(t *testing.T) {
+	episodePath := filepath.Join("downloads", "anime", "naruto", "1.mp4")
+	posterPath := player.PosterPath(episodePath, "https://animefire.plus/img/animes/naruto-large.webp?v=2")
+	assert.Equal(t, filepath.Join("downloads", "anime", "naruto", "poster.webp"), posterPath)
+
+	args := player.FFmpegCoverArgs(episodePath, posterPath, episodePath+".cover.mp4")
+
+	assert.Equal(t, []string{
+		"-y", "-v", "error",
+		"-i", episodePath, "-i", posterPath,
+		"-map", "0", "-map", "1",
+		"-c", "copy", "-c:v:1", "mjpeg", "-disposition:v:1", "attached_pic",
+		episodePath + ".cover.mp4",
+	}, args)
+}
+
+func TestPosterPathDefaultsToJPEG(t *testing.T) {
+	assert.Equal(t, filepath.Join("naruto", "poster.jpg"), player.PosterPath(filepath.Join("naruto", "1.mp4"), "https://example.com/cover"))
+}
+
+func TestFetchAnimeDetailsSetsImageURL(t *testing.T) {
+	defer api.SetHTTPDoer(testserver.New())()
+
+	anime := &api.Anime{URL: testserver.AnimeURL}
+	assert.NoError(t, api.FetchAnimeDetails(anime))
+	assert.Equal(t, "https://"+testserver.Host+"/img/stub-anime.webp", anime.ImageURL)
+}
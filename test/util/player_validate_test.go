@@ -0,0 +1,31 @@
+package test_util_test
+
+import (
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateVideoURLRejectsEmptyURL(t *testing.T) {
+	err := player.ValidateVideoURL("  ")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "empty")
+	}
+}
+
+func TestValidateVideoURLRejectsRelativeURL(t *testing.T) {
+	err := player.ValidateVideoURL("/video/naruto/1.mp4")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "absolute")
+	}
+}
+
+func TestValidateVideoURLRejectsOtherSchemes(t *testing.T) {
+	assert.Error(t, player.ValidateVideoURL("file:///etc/passwd"))
+}
+
+func TestValidateVideoURLAcceptsHLSPlaylist(t *testing.T) {
+	assert.NoError(t, player.ValidateVideoURL("https://cdn.example/hls/naruto/1/master.m3u8?token=abc"))
+	assert.NoError(t, player.ValidateVideoURL("https://www.blogger.com/video.g?token=AD6v5dx"))
+}
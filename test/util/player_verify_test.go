@@ -0,0 +1,42 @@
+package test_util_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProbeOutputValidEpisode(t *testing.T) {
+	output := `{"programs": [], "streams": [{"codec_type": "video"}, {"codec_type": "audio"}], "format": {"duration": "1420.480000"}}`
+
+	result, err := player.ParseProbeOutput([]byte(output))
+
+	assert.NoError(t, err)
+	assert.True(t, result.HasVideo)
+	assert.True(t, result.HasAudio)
+	assert.Equal(t, 1420480*time.Millisecond, result.Duration)
+	assert.NoError(t, result.Validate())
+}
+
+func TestParseProbeOutputAudioOnly(t *testing.T) {
+	result, err := player.ParseProbeOutput([]byte(`{"streams": [{"codec_type": "audio"}], "format": {"duration": "1420.5"}}`))
+
+	assert.NoError(t, err)
+	if assert.Error(t, result.Validate()) {
+		assert.Contains(t, result.Validate().Error(), "audio-only")
+	}
+}
+
+func TestParseProbeOutputTruncated(t *testing.T) {
+	result, err := player.ParseProbeOutput([]byte(`{"streams": [{"codec_type": "video"}], "format": {"duration": "3.2"}}`))
+
+	assert.NoError(t, err)
+	assert.Error(t, result.Validate())
+}
+
+func TestParseProbeOutputRejectsGarbage(t *testing.T) {
+	_, err := player.ParseProbeOutput([]byte("Invalid data found when processing input"))
+	assert.Error(t, err)
+}
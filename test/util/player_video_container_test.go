@@ -0,0 +1,67 @@
+package test_util_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/alvarorichard/Goanime/test/testserver"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	mixedHLSURL  = "https://cdn." + testserver.Host + "/stub-anime/2/1080p/index"
+	mixedFileURL = "https://cdn." + testserver.Host + "/stub-anime/2/1080p.mp4"
+)
+
+// mixedContainerServer lists episode 2 both as HLS, known only by its MIME type, and as a file in the same quality,
+// and episode 3 as HLS only.
+func mixedContainerServer() *testserver.Server {
+	server := testserver.New()
+	server.Handle("/video/stub-anime/2", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data":[{"src":"%s","label":"1080p","type":"application/x-mpegURL"},{"src":"%s","label":"1080p"},{"src":"%s","label":"360p"}]}`,
+			mixedHLSURL, mixedFileURL, testserver.StreamURL(2, "360p"))
+	})
+	server.Handle("/video/stub-anime/3", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data":[{"src":"%s","label":"1080p","type":"application/x-mpegURL"}]}`, mixedHLSURL)
+	})
+	return server
+}
+
+func TestResolvePrefersFileOverHLSInTheSameQuality(t *testing.T) {
+	defer api.SetHTTPDoer(mixedContainerServer())()
+
+	videoURL, err := player.ResolveVideoURL(testserver.EpisodeURL(2))
+	assert.NoError(t, err)
+	assert.Equal(t, mixedFileURL, videoURL)
+	assert.Equal(t, player.DownloaderHTTP, player.PreferredDownloader(videoURL))
+}
+
+func TestResolvedHLSIsDownloadedWithYtDlp(t *testing.T) {
+	defer api.SetHTTPDoer(mixedContainerServer())()
+
+	videoURL, err := player.ResolveVideoURL(testserver.EpisodeURL(3))
+	assert.NoError(t, err)
+	assert.Equal(t, mixedHLSURL, videoURL)
+	assert.Equal(t, player.DownloaderYtDlp, player.PreferredDownloader(videoURL), "the MIME type marks it as HLS though the URL doesn't")
+}
+
+func TestMixedContainersAreDistinctQualityChoices(t *testing.T) {
+	videos := []player.VideoData{
+		{Src: mixedHLSURL, Label: "1080p", Type: "application/x-mpegURL"},
+		{Src: mixedFileURL, Label: "1080p"},
+		{Src: "https://cdn.example.com/360p.m3u8", Label: "360p"},
+	}
+
+	assert.Equal(t, player.ContainerHLS, videos[0].Container())
+	assert.Equal(t, player.ContainerFile, videos[1].Container())
+	assert.Equal(t, player.ContainerHLS, videos[2].Container())
+	assert.Equal(t, []string{"1080p (HLS)", "1080p", "360p (HLS)"}, player.GetAvailableQualities(videos))
+
+	selector := player.NewQualitySelector(func(labels []string) (string, error) { return "1080p (HLS)", nil })
+	src, err := selector.Select(videos, true)
+	assert.NoError(t, err)
+	assert.Equal(t, mixedHLSURL, src)
+}
@@ -0,0 +1,45 @@
+package test_util_test
+
+import (
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYtDlpArgsResumeInterruptedDownloads(t *testing.T) {
+	args := player.YtDlpArgs("/downloads/naruto/3.mp4", "https://www.blogger.com/video.g?token=abc")
+
+	assert.Contains(t, args, "--continue")
+	assert.NotContains(t, args, "--no-part", "part files are what --continue resumes from")
+
+	// The output path must be the episode path so a re-run resumes the same part file
+	for i, arg := range args {
+		if arg == "-o" {
+			assert.Equal(t, "/downloads/naruto/3.mp4", args[i+1])
+		}
+	}
+	assert.Equal(t, "https://www.blogger.com/video.g?token=abc", args[len(args)-1])
+}
+
+func TestYtDlpArgsDownloadFragmentsConcurrently(t *testing.T) {
+	defer util.SetConfig(util.DefaultConfig())()
+
+	args := player.YtDlpArgs("/downloads/naruto/3.mp4", "https://example.com/master.m3u8")
+	assert.Subset(t, args, []string{"--concurrent-fragments", "4"})
+
+	config := util.DefaultConfig()
+	config.Threads = 8
+	util.SetConfig(config)
+	args = player.YtDlpArgs("/downloads/naruto/3.mp4", "https://example.com/master.m3u8")
+	for i, arg := range args {
+		if arg == "--concurrent-fragments" {
+			assert.Equal(t, "8", args[i+1])
+		}
+	}
+
+	config.Threads = 1
+	util.SetConfig(config)
+	assert.NotContains(t, player.YtDlpArgs("/downloads/naruto/3.mp4", "https://example.com/master.m3u8"), "--concurrent-fragments")
+}
@@ -0,0 +1,86 @@
+package test_util_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYtDlpPathRunsTheGivenBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake yt-dlp is a shell script")
+	}
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "my-yt-dlp")
+	script := "#!/bin/sh\necho \"$@\" > \"$(dirname \"$0\")/args\"\n"
+	assert.NoError(t, os.WriteFile(binary, []byte(script), 0o755))
+	assert.NoError(t, util.CheckExecutable(binary))
+
+	config := util.DefaultConfig()
+	config.YtDlpPath = binary
+	defer util.SetConfig(config)()
+
+	episodePath := filepath.Join(dir, "1.mp4")
+	cmd := player.YtDlpCommand(episodePath, "https://www.blogger.com/video.g?token=abc")
+	assert.Equal(t, binary, cmd.Path)
+	assert.NoError(t, cmd.Run())
+
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	assert.NoError(t, err)
+	assert.Contains(t, strings.TrimSpace(string(args)), "-o "+episodePath+" https://www.blogger.com/video.g?token=abc")
+}
+
+func TestCheckExecutableRejectsPlainFiles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows has no executable bit")
+	}
+	dir := t.TempDir()
+	file := filepath.Join(dir, "yt-dlp")
+	assert.NoError(t, os.WriteFile(file, []byte("not a program"), 0o644))
+
+	assert.Error(t, util.CheckExecutable(file))
+	assert.Error(t, util.CheckExecutable(dir))
+	assert.Error(t, util.CheckExecutable(filepath.Join(dir, "missing")))
+}
+
+func TestYtDlpVersionIsCheckedOncePerRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake yt-dlp is a shell script")
+	}
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "yt-dlp")
+	script := "#!/bin/sh\nif [ \"$1\" = --version ]; then echo x >> \"$(dirname \"$0\")/versions\"; echo 2020.01.01; fi\n"
+	assert.NoError(t, os.WriteFile(binary, []byte(script), 0o755))
+
+	config := util.DefaultConfig()
+	config.YtDlpPath = binary
+	defer util.SetConfig(config)()
+
+	for episode := 1; episode <= 3; episode++ {
+		cmd := player.YtDlpCommand(filepath.Join(dir, "1.mp4"), "https://www.blogger.com/video.g?token=abc")
+		assert.Equal(t, binary, cmd.Path)
+	}
+
+	versions, err := os.ReadFile(filepath.Join(dir, "versions"))
+	assert.NoError(t, err)
+	assert.Equal(t, "x\n", string(versions))
+}
+
+func TestYtDlpVersionWarning(t *testing.T) {
+	now := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	released, ok := player.YtDlpReleaseDate("2024.08.06.232903")
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2024, time.August, 6, 0, 0, 0, 0, time.UTC), released)
+
+	assert.Contains(t, player.YtDlpVersionWarning("2024.08.06", now), "yt-dlp 2024.08.06 is 299 days old")
+	assert.Empty(t, player.YtDlpVersionWarning("2025.05.22", now))
+	assert.Empty(t, player.YtDlpVersionWarning("unknown", now))
+}
@@ -0,0 +1,101 @@
+package test_util_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/server"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeServerClient answers the API with fixed data and records what it was asked for.
+type fakeServerClient struct {
+	queries []string
+	block   bool
+}
+
+func (f *fakeServerClient) Search(ctx context.Context, query string) ([]api.Anime, error) {
+	f.queries = append(f.queries, query)
+	if query == "nothing-here" {
+		return nil, api.ErrNoAnimeFound
+	}
+	return []api.Anime{{Name: "Naruto", URL: fixtureAnimeURL}}, nil
+}
+
+func (f *fakeServerClient) Episodes(ctx context.Context, animeURL string) ([]api.Episode, error) {
+	return []api.Episode{{Number: "Episódio 1", Num: 1, URL: "https://animefire.plus/animes/naruto/1"}}, nil
+}
+
+func (f *fakeServerClient) Stream(ctx context.Context, episodeURL string) (server.Stream, error) {
+	if f.block {
+		<-ctx.Done()
+		return server.Stream{}, ctx.Err()
+	}
+	return server.Stream{URL: "https://cdn.example/naruto/1.mp4"}, nil
+}
+
+func getJSON(t *testing.T, handler http.Handler, target string, out interface{}) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), out))
+	return rec.Code
+}
+
+func TestServerSearch(t *testing.T) {
+	client := &fakeServerClient{}
+	handler := server.New(client)
+
+	var animes []api.Anime
+	assert.Equal(t, http.StatusOK, getJSON(t, handler, "/search?q=Naruto+Shippuden", &animes))
+	assert.Equal(t, "Naruto", animes[0].Name)
+	assert.Equal(t, []string{"naruto-shippuden"}, client.queries)
+
+	var failure map[string]string
+	assert.Equal(t, http.StatusBadRequest, getJSON(t, handler, "/search", &failure))
+	assert.Equal(t, http.StatusNotFound, getJSON(t, handler, "/search?q=nothing+here", &failure))
+	assert.Contains(t, failure["error"], "no anime found")
+}
+
+func TestServerEpisodes(t *testing.T) {
+	handler := server.New(&fakeServerClient{})
+
+	var episodes []api.Episode
+	assert.Equal(t, http.StatusOK, getJSON(t, handler, "/episodes?url="+url.QueryEscape(fixtureAnimeURL), &episodes))
+	assert.Len(t, episodes, 1)
+
+	var failure map[string]string
+	assert.Equal(t, http.StatusBadRequest, getJSON(t, handler, "/episodes?url="+url.QueryEscape("http://169.254.169.254/latest"), &failure))
+	assert.Equal(t, http.StatusBadRequest, getJSON(t, handler, "/episodes?url="+url.QueryEscape("https://evil.example/animes/x"), &failure))
+}
+
+func TestServerStream(t *testing.T) {
+	handler := server.New(&fakeServerClient{})
+
+	var stream server.Stream
+	assert.Equal(t, http.StatusOK, getJSON(t, handler, "/stream?url="+url.QueryEscape("https://animefire.plus/animes/naruto/1"), &stream))
+	assert.Equal(t, "https://cdn.example/naruto/1.mp4", stream.URL)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/stream?url="+url.QueryEscape("https://animefire.plus/animes/naruto/1"), nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestServerStreamStopsWhenClientGoesAway(t *testing.T) {
+	handler := server.New(&fakeServerClient{block: true})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/stream?url="+url.QueryEscape("https://animefire.plus/animes/naruto/1"), nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+}
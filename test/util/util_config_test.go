@@ -0,0 +1,68 @@
+package test_util_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigCopiesDoNotInterfere(t *testing.T) {
+	defer util.SetConfig(util.DefaultConfig())()
+
+	first := util.CurrentConfig()
+	second := util.CurrentConfig()
+	first.Debug = true
+	first.PrefetchCount = 3
+	second.AcceptLanguage = "pt-BR"
+
+	assert.False(t, second.Debug)
+	assert.Empty(t, first.AcceptLanguage)
+	assert.Equal(t, util.DefaultConfig(), util.CurrentConfig())
+
+	util.SetConfig(first)
+	assert.True(t, util.CurrentConfig().Debug)
+	assert.Empty(t, util.CurrentConfig().AcceptLanguage)
+}
+
+func TestSetConfigRestoresPreviousConfig(t *testing.T) {
+	defer util.SetConfig(util.DefaultConfig())()
+
+	config := util.DefaultConfig()
+	config.MaxRedirects = 2
+	restore := util.SetConfig(config)
+	assert.Equal(t, 2, util.CurrentConfig().MaxRedirects)
+
+	restore()
+	assert.Equal(t, util.DefaultConfig().MaxRedirects, util.CurrentConfig().MaxRedirects)
+}
+
+func TestConfigConcurrentReadersSeeWholeSnapshots(t *testing.T) {
+	defer util.SetConfig(util.DefaultConfig())()
+
+	a := util.Config{Debug: true, PrefetchCount: 1, MaxRedirects: 1}
+	b := util.Config{Debug: false, PrefetchCount: 2, MaxRedirects: 2}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				util.SetConfig(a)
+				util.SetConfig(b)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c := util.CurrentConfig()
+				if c.PrefetchCount != 0 {
+					assert.Equal(t, c.PrefetchCount, c.MaxRedirects)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,35 @@
+package test_util_test
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogFileReceivesLogOutput(t *testing.T) {
+	defer util.SetConfig(util.DefaultConfig())()
+	terminal := captureLog(t)
+	path := filepath.Join(t.TempDir(), "goanime.log")
+
+	closeLog, err := util.OpenLogFile(path)
+	assert.NoError(t, err)
+
+	log.Println("Failed to fetch episodes: 403 Forbidden")
+	util.Debugf(util.WithTrace(context.Background()), "GET animefire.plus: 403 Forbidden")
+	assert.NoError(t, closeLog())
+	log.Println("after close")
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "Failed to fetch episodes: 403 Forbidden")
+	assert.Contains(t, string(data), "GET animefire.plus: 403 Forbidden", "debug lines go to the file without -debug")
+	assert.NotContains(t, string(data), "after close")
+
+	assert.Contains(t, terminal.String(), "Failed to fetch episodes")
+	assert.NotContains(t, terminal.String(), "GET animefire.plus", "debug lines stay off the terminal without -debug")
+}
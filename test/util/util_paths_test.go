@@ -0,0 +1,39 @@
+package test_util_test
+
+import (
+	"os/user"
+	"path/filepath"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/player"
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXDGDataHomeOverridesDownloadsRoot(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+
+	dir, err := util.DownloadsDir()
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dataHome, "goanime", "downloads"), dir)
+
+	animeDir, err := player.AnimeDownloadDir("https://animefire.plus/animes/naruto-todos-os-episodios")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dataHome, "goanime", "downloads", "anime"), filepath.Dir(animeDir))
+}
+
+func TestDownloadsRootFallsBackToHome(t *testing.T) {
+	currentUser, err := user.Current()
+	if err != nil {
+		t.Skip("no current user:", err)
+	}
+	fallback := filepath.Join(currentUser.HomeDir, ".local", "goanime", "downloads")
+
+	for _, dataHome := range []string{"", "relative/data"} {
+		t.Setenv("XDG_DATA_HOME", dataHome)
+		dir, err := util.DownloadsDir()
+		assert.NoError(t, err)
+		assert.Equal(t, fallback, dir, "XDG_DATA_HOME=%q", dataHome)
+	}
+}
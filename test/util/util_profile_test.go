@@ -0,0 +1,34 @@
+package test_util_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartProfileWritesNonEmptyProfiles(t *testing.T) {
+	for _, mode := range []string{"cpu", "mem"} {
+		path := filepath.Join(t.TempDir(), "goanime-"+mode+".pprof")
+
+		stop, err := util.StartProfile(mode, path)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		_ = strings.Repeat("goanime", 100000)
+		assert.NoError(t, stop())
+
+		info, err := os.Stat(path)
+		if assert.NoError(t, err) {
+			assert.Greater(t, info.Size(), int64(0), mode)
+		}
+	}
+}
+
+func TestStartProfileRejectsUnknownMode(t *testing.T) {
+	_, err := util.StartProfile("block", filepath.Join(t.TempDir(), "profile"))
+	assert.Error(t, err)
+}
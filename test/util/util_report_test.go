@@ -0,0 +1,39 @@
+package test_util_test
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueReportIncludesCapturedFields(t *testing.T) {
+	report := util.NewIssueReport("goanime -report-source naruto", "AnimeFire (animefire.plus)")
+	assert.True(t, report.AddStep(`Search for "naruto"`, nil))
+	assert.False(t, report.AddStep("Resolve the video of episode 1",
+		errors.New(`failed to fetch video source: Get "https://cdn.example/1.mp4?token=secret&expires=123": 403 Forbidden`)))
+
+	body := report.Markdown()
+
+	assert.Contains(t, body, "Resolve the video of episode 1 failed.")
+	assert.Contains(t, body, "1. Run `goanime -report-source naruto`")
+	assert.Contains(t, body, `2. Search for "naruto": ok`)
+	assert.Contains(t, body, "3. Resolve the video of episode 1: failed")
+	assert.Contains(t, body, `"https://cdn.example/1.mp4?token=REDACTED&expires=REDACTED": 403 Forbidden`)
+	assert.NotContains(t, body, "secret")
+	assert.Contains(t, body, "- OS: "+runtime.GOOS+"/"+runtime.GOARCH)
+	assert.Contains(t, body, "- Go version: "+runtime.Version())
+	assert.Contains(t, body, "- Source: AnimeFire (animefire.plus)")
+	assert.Contains(t, body, "- GoAnime version: ")
+}
+
+func TestIssueReportWithoutFailure(t *testing.T) {
+	report := util.NewIssueReport("goanime -report-source naruto", "AnimeFire (animefire.plus)")
+	report.AddStep(`Search for "naruto"`, nil)
+
+	_, failed := report.Failed()
+	assert.False(t, failed)
+	assert.NotContains(t, report.Markdown(), "### Error")
+}
@@ -0,0 +1,54 @@
+package test_util_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindWithContextReturnsPromptlyWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	closed := make(chan struct{})
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	start := time.Now()
+	idx, err := util.FindWithContext(ctx, func(ctx context.Context) (int, error) {
+		// A finder nobody answers, which closes once its context is done
+		<-ctx.Done()
+		close(closed)
+		return 0, errors.New("abort")
+	})
+
+	assert.Equal(t, -1, idx)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	<-closed
+}
+
+func TestFindWithContextTimesOutStuckFinder(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	block := make(chan struct{})
+	defer close(block)
+
+	_, err := util.FindWithContext(ctx, func(context.Context) (int, error) {
+		<-block
+		return 0, nil
+	})
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestFindWithContextReturnsSelection(t *testing.T) {
+	idx, err := util.FindWithContext(context.Background(), func(context.Context) (int, error) { return 3, nil })
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, idx)
+}
@@ -0,0 +1,69 @@
+package test_util_test
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"regexp"
+	"testing"
+
+	"github.com/alvarorichard/Goanime/internal/api"
+	"github.com/alvarorichard/Goanime/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// traceLineRe matches the traced lines the test writes and the request lines, leaving out the response statuses.
+var traceLineRe = regexp.MustCompile(`(?m)\[trace ([0-9a-f]{8})\] (resolving|GET animefire\.plus)$`)
+
+// captureLog redirects the standard logger for the rest of the test.
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	flags, output := log.Flags(), log.Writer()
+	log.SetFlags(0)
+	log.SetOutput(&buf)
+	t.Cleanup(func() {
+		log.SetFlags(flags)
+		log.SetOutput(output)
+	})
+	return &buf
+}
+
+func TestTracedOperationSharesOneID(t *testing.T) {
+	config := util.DefaultConfig()
+	config.Debug = true
+	defer util.SetConfig(config)()
+	defer api.SetHTTPDoer(&fakeDoer{bodies: map[string]string{
+		"https://animefire.plus/a": "a",
+	}})()
+	logs := captureLog(t)
+
+	first := util.WithTrace(context.Background())
+	second := util.WithTrace(context.Background())
+	util.Debugf(first, "resolving")
+	for _, ctx := range []context.Context{first, second, first} {
+		resp, err := api.SafeGetContext(ctx, "https://animefire.plus/a")
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+	}
+
+	matches := traceLineRe.FindAllStringSubmatch(logs.String(), -1)
+	if !assert.Len(t, matches, 4) {
+		return
+	}
+	assert.Equal(t, "resolving", matches[0][2])
+	assert.Equal(t, "GET animefire.plus", matches[1][2])
+	assert.Equal(t, util.TraceID(first), matches[0][1])
+	assert.Equal(t, matches[0][1], matches[1][1])
+	assert.Equal(t, matches[0][1], matches[3][1])
+	assert.Equal(t, util.TraceID(second), matches[2][1])
+	assert.NotEqual(t, matches[0][1], matches[2][1])
+}
+
+func TestWithTraceKeepsExistingID(t *testing.T) {
+	ctx := util.WithTrace(context.Background())
+
+	assert.Equal(t, util.TraceID(ctx), util.TraceID(util.WithTrace(ctx)))
+	assert.Empty(t, util.TraceID(context.Background()))
+}